@@ -2,10 +2,16 @@ module link-builder
 
 go 1.24.2
 
-require github.com/tiendc/go-linkpreview v0.0.0-20240619195214-ed28db0d225e
+require (
+	github.com/fxamacker/cbor/v2 v2.9.2
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/tiendc/go-linkpreview v0.0.0-20240619195214-ed28db0d225e
+	golang.org/x/net v0.39.0
+)
 
 require (
 	github.com/PuerkitoBio/goquery v1.10.3 // indirect
 	github.com/andybalholm/cascadia v1.3.3 // indirect
-	golang.org/x/net v0.39.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/text v0.24.0 // indirect
 )
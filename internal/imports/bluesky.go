@@ -0,0 +1,450 @@
+package imports
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"link-builder/internal/types"
+)
+
+const (
+	// maxCARSectionLength bounds the length of a CAR header or block read
+	// from the file. Repository blocks are records and tree nodes of a few
+	// KiB at most; blobs are not part of repository exports.
+	maxCARSectionLength = 4 << 20
+	// cborMapOfTwo is the DAG-CBOR initial byte of a map with two entries.
+	cborMapOfTwo = 0xa2
+)
+
+const (
+	blueskyPostCollection = "app.bsky.feed.post"
+	blueskyLinkFeature    = "app.bsky.richtext.facet#link"
+	blueskyTagFeature     = "app.bsky.richtext.facet#tag"
+)
+
+// BlueskyImporter reads Bluesky posts, either from a repository export in CAR
+// format as downloaded from the account settings or com.atproto.sync.getRepo,
+// or from the JSON returned by com.atproto.repo.listRecords. Link facets and
+// external embeds yield records with the post URL as source, tag facets become
+// tags.
+type BlueskyImporter struct{}
+
+type blueskyPost struct {
+	Type      string `json:"$type"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"createdAt"`
+	Facets    []struct {
+		Index struct {
+			ByteStart int `json:"byteStart"`
+			ByteEnd   int `json:"byteEnd"`
+		} `json:"index"`
+		Features []struct {
+			Type string `json:"$type"`
+			URI  string `json:"uri"`
+			Tag  string `json:"tag"`
+		} `json:"features"`
+	} `json:"facets"`
+	Embed *struct {
+		External *struct {
+			URI         string `json:"uri"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		} `json:"external"`
+	} `json:"embed"`
+}
+
+// blueskyRecord is an entry of com.atproto.repo.listRecords.
+type blueskyRecord struct {
+	URI   string          `json:"uri"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (BlueskyImporter) Name() string {
+	return "bluesky"
+}
+
+func (BlueskyImporter) Detect(prefix []byte) bool {
+	trimmed := bytes.TrimSpace(prefix)
+	if bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("[")) {
+		return bytes.Contains(trimmed, []byte(`"`+blueskyPostCollection+`"`))
+	}
+	// A CAR file starts with the length of its DAG-CBOR header, a map of
+	// roots and version.
+	length, n := binary.Uvarint(prefix)
+	if n <= 0 || length == 0 || length > uint64(len(prefix)-n) {
+		return false
+	}
+	_, err := decodeCARHeader(prefix[n : n+int(length)])
+	return err == nil
+}
+
+func (BlueskyImporter) Extract(r io.Reader, _ Options) (Result, error) {
+	reader := bufio.NewReader(r)
+	first, err := peekNonSpace(reader)
+	if err != nil {
+		return Result{}, fmt.Errorf("reading Bluesky export: %w", err)
+	}
+	var allURLs []types.URLRecord
+	if first == '{' || first == '[' {
+		allURLs, err = extractBlueskyJSON(reader)
+	} else {
+		allURLs, err = extractBlueskyCAR(reader)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Records: allURLs}, nil
+}
+
+func extractBlueskyJSON(r io.Reader) ([]types.URLRecord, error) {
+	var data json.RawMessage
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, fmt.Errorf("parsing Bluesky JSON: %w", err)
+	}
+	var listing struct {
+		Records []blueskyRecord `json:"records"`
+	}
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &listing.Records); err != nil {
+			return nil, fmt.Errorf("parsing Bluesky JSON: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &listing); err != nil {
+		return nil, fmt.Errorf("parsing Bluesky JSON: %w", err)
+	}
+
+	allURLs := []types.URLRecord{}
+	for _, record := range listing.Records {
+		did, rkey, ok := parseBlueskyPostURI(record.URI)
+		if !ok {
+			continue
+		}
+		var post blueskyPost
+		if err := json.Unmarshal(record.Value, &post); err != nil {
+			return nil, fmt.Errorf("parsing Bluesky post %s: %w", record.URI, err)
+		}
+		allURLs = append(allURLs, post.records(blueskyPostURL(did, rkey), rkey)...)
+	}
+	return allURLs, nil
+}
+
+// parseBlueskyPostURI splits an at://did/app.bsky.feed.post/rkey URI.
+func parseBlueskyPostURI(uri string) (string, string, bool) {
+	parts := strings.Split(strings.TrimPrefix(uri, "at://"), "/")
+	if len(parts) != 3 || parts[1] != blueskyPostCollection {
+		return "", "", false
+	}
+	return parts[0], parts[2], true
+}
+
+func blueskyPostURL(did, rkey string) string {
+	return "https://bsky.app/profile/" + did + "/post/" + rkey
+}
+
+// extractBlueskyCAR reads a repository export. The blocks are held in memory
+// to look up the posts from the Merkle search tree of the repository commit.
+func extractBlueskyCAR(r io.Reader) ([]types.URLRecord, error) {
+	roots, blocks, err := readCAR(r)
+	if err != nil {
+		return nil, fmt.Errorf("parsing Bluesky CAR file: %w", err)
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("parsing Bluesky CAR file: no root commit")
+	}
+	var commit struct {
+		DID  string  `cbor:"did"`
+		Data carLink `cbor:"data"`
+	}
+	if err = decodeBlock(blocks, roots[0], &commit); err != nil {
+		return nil, fmt.Errorf("parsing Bluesky repository commit: %w", err)
+	}
+
+	allURLs := []types.URLRecord{}
+	err = walkMST(blocks, commit.Data, func(key string, value carLink) error {
+		collection, rkey, found := strings.Cut(key, "/")
+		if !found || collection != blueskyPostCollection {
+			return nil
+		}
+		var post blueskyPost
+		if err := decodeBlock(blocks, value, &post); err != nil {
+			return fmt.Errorf("post %s: %w", rkey, err)
+		}
+		allURLs = append(allURLs, post.records(blueskyPostURL(commit.DID, rkey), rkey)...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("parsing Bluesky repository: %w", err)
+	}
+	return allURLs, nil
+}
+
+// records returns a record for every distinct link of the post.
+func (p blueskyPost) records(postURL, rkey string) []types.URLRecord {
+	date := p.date(rkey)
+	var links []textLink
+	var tags []string
+	for _, facet := range p.Facets {
+		for _, feature := range facet.Features {
+			switch feature.Type {
+			case blueskyLinkFeature:
+				label := facetText(p.Text, facet.Index.ByteStart, facet.Index.ByteEnd)
+				links = append(links, textLink{url: feature.URI, text: linkLabel(label, feature.URI)})
+			case blueskyTagFeature:
+				tags = appendTag(tags, feature.Tag)
+			}
+		}
+	}
+
+	var records []types.URLRecord
+	index := make(map[string]int)
+	for _, link := range links {
+		if _, exists := index[link.url]; exists || link.url == "" {
+			continue
+		}
+		index[link.url] = len(records)
+		records = append(records, types.URLRecord{Date: date, URL: link.url, Text: link.text, Source: postURL})
+	}
+	if p.Embed != nil && p.Embed.External != nil && p.Embed.External.URI != "" {
+		external := p.Embed.External
+		hint := &types.PreviewHint{Title: external.Title, Description: external.Description}
+		if i, exists := index[external.URI]; exists {
+			records[i].Hint = hint
+		} else {
+			records = append(records, types.URLRecord{
+				Date:   date,
+				URL:    external.URI,
+				Text:   external.Title,
+				Source: postURL,
+				Hint:   hint,
+			})
+		}
+	}
+	for i := range records {
+		records[i].Tags = slices.Clone(tags)
+	}
+	return records
+}
+
+// date returns the creation date of the post. Posts without createdAt are
+// dated by their record key if it is a timestamp identifier, or else left
+// undated.
+func (p blueskyPost) date(rkey string) string {
+	if strings.TrimSpace(p.CreatedAt) != "" {
+		return sourceDate(p.CreatedAt, time.RFC3339)
+	}
+	if created, ok := parseTID(rkey); ok {
+		return formatDate(created)
+	}
+	return ""
+}
+
+// parseTID returns the time of a timestamp identifier: 13 base32-sortable
+// characters of a 64-bit integer holding the microseconds since the Unix
+// epoch above a 10-bit clock identifier.
+func parseTID(tid string) (time.Time, bool) {
+	const alphabet = "234567abcdefghijklmnopqrstuvwxyz"
+	if len(tid) != 13 || strings.IndexByte("234567abcdefghij", tid[0]) == -1 {
+		return time.Time{}, false
+	}
+	var value uint64
+	for i := range len(tid) {
+		digit := strings.IndexByte(alphabet, tid[i])
+		if digit == -1 {
+			return time.Time{}, false
+		}
+		value = value<<5 | uint64(digit)
+	}
+	return time.UnixMicro(int64(value >> 10)), true
+}
+
+// facetText returns the text a facet refers to. Facet indices are UTF-8 byte
+// offsets.
+func facetText(text string, start, end int) string {
+	if start < 0 || end > len(text) || start >= end {
+		return ""
+	}
+	return text[start:end]
+}
+
+// carLink is a binary CID, used both as a block key of a CAR file and for the
+// links between DAG-CBOR blocks.
+type carLink string
+
+// UnmarshalCBOR decodes a DAG-CBOR link, tag 42 holding the CID bytes behind
+// a zero byte.
+func (l *carLink) UnmarshalCBOR(data []byte) error {
+	var tag cbor.Tag
+	if err := cbor.Unmarshal(data, &tag); err != nil {
+		return err
+	}
+	content, ok := tag.Content.([]byte)
+	if tag.Number != 42 || !ok || len(content) < 2 || content[0] != 0 {
+		return errors.New("invalid CID link")
+	}
+	*l = carLink(content[1:])
+	return nil
+}
+
+// readCAR reads the roots and all blocks of a CAR v1 file.
+func readCAR(r io.Reader) ([]carLink, map[carLink][]byte, error) {
+	reader := bufio.NewReader(r)
+	headerData, err := readCARSection(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading header: %w", err)
+	}
+	header, err := decodeCARHeader(headerData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blocks := make(map[carLink][]byte)
+	for {
+		section, err := readCARSection(reader)
+		if errors.Is(err, io.EOF) {
+			return header.Roots, blocks, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading block: %w", err)
+		}
+		length, err := cidLength(section)
+		if err != nil {
+			return nil, nil, err
+		}
+		blocks[carLink(section[:length])] = section[length:]
+	}
+}
+
+type carHeader struct {
+	Version int       `cbor:"version"`
+	Roots   []carLink `cbor:"roots"`
+}
+
+// decodeCARHeader decodes the header of a CAR v1 file, a DAG-CBOR map of the
+// roots and the version.
+func decodeCARHeader(data []byte) (carHeader, error) {
+	var header carHeader
+	if len(data) == 0 || data[0] != cborMapOfTwo {
+		return header, errors.New("decoding header: not a map of roots and version")
+	}
+	if err := cbor.Unmarshal(data, &header); err != nil {
+		return header, fmt.Errorf("decoding header: %w", err)
+	}
+	if header.Version != 1 {
+		return header, fmt.Errorf("unsupported CAR version %d", header.Version)
+	}
+	return header, nil
+}
+
+// readCARSection reads a varint length prefixed section.
+func readCARSection(reader *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+	if length > maxCARSectionLength {
+		return nil, fmt.Errorf("section of %d bytes exceeds the limit of %d bytes", length, maxCARSectionLength)
+	}
+	section := make([]byte, length)
+	if _, err = io.ReadFull(reader, section); err != nil {
+		return nil, err
+	}
+	return section, nil
+}
+
+// cidLength returns the length of the binary CID at the start of data: a bare
+// sha2-256 multihash for CIDv0, otherwise the version, codec, hash function
+// and digest length varints followed by the digest.
+func cidLength(data []byte) (int, error) {
+	if len(data) >= 34 && data[0] == 0x12 && data[1] == 0x20 {
+		return 34, nil
+	}
+	offset := 0
+	var value uint64
+	for range 4 {
+		var n int
+		if value, n = binary.Uvarint(data[offset:]); n <= 0 {
+			return 0, errors.New("invalid CID")
+		}
+		offset += n
+	}
+	if value > uint64(len(data)-offset) {
+		return 0, errors.New("invalid CID digest length")
+	}
+	return offset + int(value), nil
+}
+
+func decodeBlock(blocks map[carLink][]byte, link carLink, value any) error {
+	data, ok := blocks[link]
+	if !ok {
+		return errors.New("block missing from CAR file")
+	}
+	return cbor.Unmarshal(data, value)
+}
+
+type mstNode struct {
+	Left    *carLink   `cbor:"l"`
+	Entries []mstEntry `cbor:"e"`
+}
+
+// mstEntry is a key of a Merkle search tree node. Keys share PrefixLength
+// bytes with the previous key of the node.
+type mstEntry struct {
+	PrefixLength int      `cbor:"p"`
+	KeySuffix    []byte   `cbor:"k"`
+	Value        carLink  `cbor:"v"`
+	Right        *carLink `cbor:"t"`
+}
+
+// walkMST calls visit for every key of the Merkle search tree at link in key
+// order.
+func walkMST(blocks map[carLink][]byte, link carLink, visit func(key string, value carLink) error) error {
+	return walkMSTNode(blocks, link, make(map[carLink]bool), visit)
+}
+
+// walkMSTNode walks the subtree at link. Nodes already in visited are
+// rejected, so that a cyclic tree cannot recurse forever.
+func walkMSTNode(
+	blocks map[carLink][]byte,
+	link carLink,
+	visited map[carLink]bool,
+	visit func(key string, value carLink) error,
+) error {
+	if visited[link] {
+		return errors.New("tree node linked more than once")
+	}
+	visited[link] = true
+	var node mstNode
+	if err := decodeBlock(blocks, link, &node); err != nil {
+		return fmt.Errorf("tree node: %w", err)
+	}
+	if node.Left != nil {
+		if err := walkMSTNode(blocks, *node.Left, visited, visit); err != nil {
+			return err
+		}
+	}
+	var key []byte
+	for _, entry := range node.Entries {
+		if entry.PrefixLength > len(key) {
+			return errors.New("invalid tree key prefix")
+		}
+		key = append(key[:entry.PrefixLength:entry.PrefixLength], entry.KeySuffix...)
+		if err := visit(string(key), entry.Value); err != nil {
+			return err
+		}
+		if entry.Right != nil {
+			if err := walkMSTNode(blocks, *entry.Right, visited, visit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package imports_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+// carBuilder assembles a CAR v1 file from DAG-CBOR blocks.
+type carBuilder struct {
+	t      *testing.T
+	blocks bytes.Buffer
+}
+
+// add encodes value as a block and returns a link to it.
+func (b *carBuilder) add(value any) cbor.Tag {
+	b.t.Helper()
+	data, err := cbor.Marshal(value)
+	if err != nil {
+		b.t.Fatalf("Failed to encode block: %v", err)
+	}
+	digest := sha256.Sum256(data)
+	cid := append([]byte{0x01, 0x71, 0x12, 0x20}, digest[:]...)
+	b.blocks.Write(binary.AppendUvarint(nil, uint64(len(cid)+len(data))))
+	b.blocks.Write(cid)
+	b.blocks.Write(data)
+	return cbor.Tag{Number: 42, Content: append([]byte{0}, cid...)}
+}
+
+func (b *carBuilder) bytes(root cbor.Tag) []byte {
+	b.t.Helper()
+	header, err := cbor.Marshal(map[string]any{"version": 1, "roots": []any{root}})
+	if err != nil {
+		b.t.Fatalf("Failed to encode header: %v", err)
+	}
+	car := binary.AppendUvarint(nil, uint64(len(header)))
+	car = append(car, header...)
+	return append(car, b.blocks.Bytes()...)
+}
+
+func TestProcessImportBlueskyCAR(t *testing.T) {
+	builder := &carBuilder{t: t}
+	first := builder.add(map[string]any{
+		"$type":     "app.bsky.feed.post",
+		"text":      "Reading go.dev/blog #golang",
+		"createdAt": "2024-05-01T10:00:00.000Z",
+		"facets": []any{
+			map[string]any{
+				"index":    map[string]any{"byteStart": 8, "byteEnd": 19},
+				"features": []any{map[string]any{"$type": "app.bsky.richtext.facet#link", "uri": "https://go.dev/blog"}},
+			},
+			map[string]any{
+				"index":    map[string]any{"byteStart": 20, "byteEnd": 27},
+				"features": []any{map[string]any{"$type": "app.bsky.richtext.facet#tag", "tag": "golang"}},
+			},
+		},
+	})
+	second := builder.add(map[string]any{
+		"$type":     "app.bsky.feed.post",
+		"text":      "Nice article",
+		"createdAt": "2024-05-02T08:00:00Z",
+		"embed": map[string]any{
+			"$type":    "app.bsky.embed.external",
+			"external": map[string]any{"uri": "https://example.com/article", "title": "Article", "description": "About it"},
+		},
+	})
+	follow := builder.add(map[string]any{"$type": "app.bsky.graph.follow", "subject": "did:plc:bob"})
+	tree := builder.add(map[string]any{
+		"l": nil,
+		"e": []any{
+			map[string]any{"p": 0, "k": []byte("app.bsky.feed.post/3kaaa"), "v": first, "t": nil},
+			map[string]any{"p": 19, "k": []byte("3kbbb"), "v": second, "t": nil},
+			map[string]any{"p": 9, "k": []byte("graph.follow/3kccc"), "v": follow, "t": nil},
+		},
+	})
+	commit := builder.add(map[string]any{"did": "did:plc:ada", "version": 3, "data": tree, "rev": "3kddd", "sig": []byte{1}})
+
+	tempInputFile := filepath.Join(t.TempDir(), "repo.car")
+	if err := os.WriteFile(tempInputFile, builder.bytes(commit), 0600); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_bluesky_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{
+			ID:     1,
+			Date:   "2024-05-01T10:00:00Z",
+			URL:    "https://go.dev/blog",
+			Tags:   []string{"golang"},
+			Source: "https://bsky.app/profile/did:plc:ada/post/3kaaa",
+		},
+		{
+			ID:     2,
+			Date:   "2024-05-02T08:00:00Z",
+			URL:    "https://example.com/article",
+			Text:   "Article",
+			Source: "https://bsky.app/profile/did:plc:ada/post/3kbbb",
+			Hint:   &types.PreviewHint{Title: "Article", Description: "About it"},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestProcessImportBlueskyJSON(t *testing.T) {
+	mockInput := `{"records": [
+		{
+			"uri": "at://did:plc:ada/app.bsky.feed.post/3kaaa",
+			"cid": "bafyrei",
+			"value": {
+				"$type": "app.bsky.feed.post",
+				"text": "See the docs",
+				"createdAt": "2024-05-01T10:00:00Z",
+				"facets": [{"index": {"byteStart": 4, "byteEnd": 12}, "features": [{"$type": "app.bsky.richtext.facet#link", "uri": "https://go.dev/doc/"}]}]
+			}
+		}
+	], "cursor": "3kaaa"}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_bluesky_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_bluesky_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{{
+		ID:     1,
+		Date:   "2024-05-01T10:00:00Z",
+		URL:    "https://go.dev/doc/",
+		Text:   "the docs",
+		Source: "https://bsky.app/profile/did:plc:ada/post/3kaaa",
+	}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestBlueskyImporterCorruptCAR(t *testing.T) {
+	builder := &carBuilder{t: t}
+	// The commit links to a tree node that is not part of the file.
+	missing := cbor.Tag{Number: 42, Content: append([]byte{0, 0x01, 0x71, 0x12, 0x20}, make([]byte, 32)...)}
+	commit := builder.add(map[string]any{"did": "did:plc:ada", "version": 3, "data": missing})
+	valid := builder.bytes(commit)
+
+	// A tree node whose left subtree is the node itself. Blocks are looked up
+	// by CID without verifying their digest, so any CID will do.
+	cycleBuilder := &carBuilder{t: t}
+	cid := append([]byte{0x01, 0x71, 0x12, 0x20}, bytes.Repeat([]byte{1}, 32)...)
+	self := cbor.Tag{Number: 42, Content: append([]byte{0}, cid...)}
+	data, err := cbor.Marshal(map[string]any{"l": self, "e": []any{}})
+	if err != nil {
+		t.Fatalf("Failed to encode block: %v", err)
+	}
+	cycleBuilder.blocks.Write(binary.AppendUvarint(nil, uint64(len(cid)+len(data))))
+	cycleBuilder.blocks.Write(cid)
+	cycleBuilder.blocks.Write(data)
+	cycleCommit := cycleBuilder.add(map[string]any{"did": "did:plc:ada", "version": 3, "data": self})
+
+	inputs := map[string][]byte{
+		"HugeSection": append(slices.Clone(valid), binary.AppendUvarint(nil, 1<<62)...),
+		"Truncated":   valid[:len(valid)-10],
+		"MissingTree": valid,
+		"CyclicTree":  cycleBuilder.bytes(cycleCommit),
+	}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := (imports.BlueskyImporter{}).Extract(bytes.NewReader(input), imports.Options{}); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestBlueskyImporterDetectPlainText(t *testing.T) {
+	for _, input := range []string{
+		"Release version notes – roots of the issue",
+		"\x3aroots and version",
+		"\x05\xa2roots version",
+	} {
+		if (imports.BlueskyImporter{}).Detect([]byte(input)) {
+			t.Errorf("Expected %q not to be detected as Bluesky export", input)
+		}
+	}
+}
+
+func TestBlueskyImporterUndatedPosts(t *testing.T) {
+	input := `{"records": [
+		{"uri": "at://did:plc:ada/app.bsky.feed.post/3krl2hnms222b",
+		 "value": {"text": "https://example.com/a", "facets": [{"index": {"byteStart": 0, "byteEnd": 21},
+			"features": [{"$type": "app.bsky.richtext.facet#link", "uri": "https://example.com/a"}]}]}},
+		{"uri": "at://did:plc:ada/app.bsky.feed.post/custom",
+		 "value": {"text": "https://example.com/b", "createdAt": "someday", "facets": [{"index": {"byteStart": 0, "byteEnd": 21},
+			"features": [{"$type": "app.bsky.richtext.facet#link", "uri": "https://example.com/b"}]}]}}
+	]}`
+	result, err := (imports.BlueskyImporter{}).Extract(strings.NewReader(input), imports.Options{})
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %+v", result.Records)
+	}
+	// The record key is a timestamp identifier of the creation time.
+	if date := result.Records[0].Date; date != "2024-05-03T08:00:00Z" {
+		t.Errorf("Expected the date of the record key, got %q", date)
+	}
+	// Invalid dates are kept for the import pipeline to flag.
+	if date := result.Records[1].Date; date != "someday" {
+		t.Errorf("Expected the invalid date as found, got %q", date)
+	}
+}
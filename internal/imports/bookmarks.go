@@ -0,0 +1,123 @@
+package imports
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"link-builder/internal/types"
+)
+
+// BookmarksImporter reads the Netscape Bookmark File format, the HTML format
+// every browser uses for bookmark exports. Folder names and the TAGS attribute
+// become record tags, ADD_DATE becomes the record date.
+type BookmarksImporter struct{}
+
+func (BookmarksImporter) Name() string {
+	return "bookmarks"
+}
+
+func (BookmarksImporter) Detect(prefix []byte) bool {
+	lower := bytes.ToLower(prefix)
+	return bytes.Contains(lower, []byte("netscape-bookmark-file")) ||
+		bytes.Contains(lower, []byte("<dt><a "))
+}
+
+func (BookmarksImporter) Extract(r io.Reader, _ Options) (Result, error) {
+	allURLs, err := extractBookmarkURLs(r)
+	if err != nil {
+		return Result{}, fmt.Errorf("parsing bookmarks HTML: %w", err)
+	}
+	return Result{Records: allURLs}, nil
+}
+
+// extractBookmarkURLs walks the <DL> folder tree of a bookmarks file and
+// returns one record per <A HREF> element.
+func extractBookmarkURLs(r io.Reader) ([]types.URLRecord, error) {
+	allURLs := []types.URLRecord{}
+	tokenizer := html.NewTokenizer(r)
+
+	var (
+		folders       []string
+		pendingFolder string
+		inFolderName  bool
+		current       *types.URLRecord
+	)
+
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return nil, err
+			}
+			return allURLs, nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.H3:
+				inFolderName = true
+				pendingFolder = ""
+			case atom.Dl:
+				folders = append(folders, pendingFolder)
+				pendingFolder = ""
+			case atom.A:
+				allURLs = append(allURLs, bookmarkRecord(token, folders))
+				current = &allURLs[len(allURLs)-1]
+			default:
+			}
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.H3:
+				inFolderName = false
+			case atom.Dl:
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+			case atom.A:
+				if current != nil {
+					current.Text = strings.TrimSpace(current.Text)
+				}
+				current = nil
+			default:
+			}
+		case html.TextToken:
+			text := string(tokenizer.Text())
+			if inFolderName {
+				pendingFolder += text
+			} else if current != nil {
+				current.Text += text
+			}
+		case html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
+
+func bookmarkRecord(token html.Token, folders []string) types.URLRecord {
+	record := types.URLRecord{}
+	for _, folder := range folders {
+		if folder = strings.TrimSpace(folder); folder != "" {
+			record.Tags = appendTag(record.Tags, folder)
+		}
+	}
+	for _, attr := range token.Attr {
+		switch attr.Key {
+		case "href":
+			record.URL = attr.Val
+		case "add_date":
+			record.Date = unixDate(attr.Val)
+		case "tags":
+			for _, tag := range strings.Split(attr.Val, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					record.Tags = appendTag(record.Tags, tag)
+				}
+			}
+		}
+	}
+	return record
+}
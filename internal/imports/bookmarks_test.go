@@ -0,0 +1,70 @@
+package imports_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportBookmarks(t *testing.T) {
+	mockInput := `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1700000000">Dev</H3>
+    <DL><p>
+        <DT><H3>Go</H3>
+        <DL><p>
+            <DT><A HREF="https://go.dev/blog/" ADD_DATE="1714521600" TAGS="go,blog">The Go &amp; Blog</A>
+        </DL><p>
+        <DT><A HREF="https://example.org/" ADD_DATE="1714608000000">Example</A>
+    </DL><p>
+    <DT><A HREF="https://example.com/" ADD_DATE="1714694400">Top level</A>
+    <DT><A HREF="place:sort=8&maxResults=10">Recent Tags</A>
+</DL><p>
+`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_bookmarks_input.html")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_bookmarks_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{Format: imports.FormatAuto}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{
+			ID:   1,
+			Date: "2024-05-01T00:00:00Z",
+			URL:  "https://go.dev/blog/",
+			Text: "The Go & Blog",
+			Tags: []string{"Dev", "Go", "go", "blog"},
+		},
+		{ID: 2, Date: "2024-05-02T00:00:00Z", URL: "https://example.org/", Text: "Example", Tags: []string{"Dev"}},
+		{ID: 3, Date: "2024-05-03T00:00:00Z", URL: "https://example.com/", Text: "Top level"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestProcessImportBookmarksMissingFile(t *testing.T) {
+	tempOutputFile := utils.CreateTempFile(t, "", "missing_bookmarks_output.json")
+	defer os.Remove(tempOutputFile)
+
+	options := imports.Options{Format: "bookmarks"}
+	if err := imports.ProcessImport("non_existent_bookmarks.html", tempOutputFile, options); err == nil {
+		t.Errorf("Expected error for missing bookmarks file, got nil")
+	}
+}
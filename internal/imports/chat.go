@@ -0,0 +1,207 @@
+package imports
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"link-builder/internal/types"
+)
+
+// chatDateLayouts are the message timestamp formats of WhatsApp and Signal
+// text exports, tried in order after Options.DateLayouts. Day-first dates
+// take precedence over month-first ones, except for 12-hour clocks, which
+// are mostly used with month-first dates.
+func chatDateLayouts() []string {
+	return []string{
+		"02/01/2006, 15:04:05",
+		"02/01/2006, 15:04",
+		"02/01/06, 15:04:05",
+		"02/01/06, 15:04",
+		"2/1/2006, 15:04:05",
+		"2/1/2006, 15:04",
+		"1/2/06, 3:04:05 PM",
+		"1/2/06, 3:04 PM",
+		"1/2/2006, 3:04:05 PM",
+		"1/2/2006, 3:04 PM",
+		"1/2/06, 15:04",
+		"02.01.06, 15:04:05",
+		"02.01.06, 15:04",
+		"02.01.2006, 15:04:05",
+		"02.01.2006, 15:04",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02, 15:04:05",
+		"2006-01-02, 15:04",
+	}
+}
+
+// ChatTextImporter reads the plain-text chat exports of WhatsApp ("Export
+// chat") and Signal. Each message starts with a line like
+// "31/12/2023, 22:15 - Alice: text" or "[2023-12-31 22:15:03] Alice: text";
+// following lines without a timestamp continue the message. Every URL of a
+// message yields a record with the message date and the line of the message;
+// Options.MessageContext adds the sender as context.from.
+type ChatTextImporter struct{}
+
+func (ChatTextImporter) Name() string {
+	return "chat"
+}
+
+func (ChatTextImporter) Detect(prefix []byte) bool {
+	for _, line := range strings.Split(string(prefix), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			_, ok := parseChatHeader(line, chatDateLayouts())
+			return ok
+		}
+	}
+	return false
+}
+
+func (importer ChatTextImporter) Extract(r io.Reader, options Options) (Result, error) {
+	return importer.extract(r, "", options)
+}
+
+func (importer ChatTextImporter) ExtractFile(path string, r io.Reader, options Options) (Result, error) {
+	return importer.extract(r, path, options)
+}
+
+func (ChatTextImporter) extract(r io.Reader, source string, options Options) (Result, error) {
+	parser := chatTextParser{
+		pattern: textLinkPattern(),
+		layouts: append(append([]string{}, options.DateLayouts...), chatDateLayouts()...),
+		source:  source,
+		chat:    chatName(source),
+		options: options,
+		records: []types.URLRecord{},
+	}
+	if err := parser.read(r); err != nil {
+		return Result{}, fmt.Errorf("reading chat export: %w", err)
+	}
+	return Result{Records: parser.records}, nil
+}
+
+// chatName derives the chat name from the file name WhatsApp gives exports,
+// e.g. "WhatsApp Chat with Family.txt".
+func chatName(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for _, prefix := range []string{"WhatsApp Chat with ", "WhatsApp Chat - "} {
+		if strings.HasPrefix(name, prefix) {
+			return strings.TrimPrefix(name, prefix)
+		}
+	}
+	return ""
+}
+
+// chatMessage is a message of a chat export, possibly spanning several lines.
+type chatMessage struct {
+	line   int
+	date   time.Time
+	sender string
+	text   string
+}
+
+// parseChatHeader parses the first line of a message. System messages such
+// as "Alice joined" have no sender.
+func parseChatHeader(line string, layouts []string) (chatMessage, bool) {
+	line = strings.TrimLeft(line, "\u200e\ufeff")
+	var stamp, rest string
+	if strings.HasPrefix(line, "[") {
+		end := strings.Index(line, "] ")
+		if end == -1 {
+			return chatMessage{}, false
+		}
+		stamp, rest = line[1:end], line[end+2:]
+	} else {
+		var found bool
+		if stamp, rest, found = strings.Cut(line, " - "); !found {
+			return chatMessage{}, false
+		}
+	}
+
+	// Recent WhatsApp versions put a narrow no-break space before AM/PM.
+	stamp = strings.NewReplacer("\u202f", " ", "\u00a0", " ").Replace(stamp)
+	for _, layout := range layouts {
+		date, err := time.Parse(layout, stamp)
+		if err != nil {
+			continue
+		}
+		message := chatMessage{date: date, text: rest}
+		if sender, text, found := strings.Cut(rest, ": "); found {
+			message.sender = strings.TrimLeft(sender, "\u200e~ ")
+			message.text = text
+		}
+		return message, true
+	}
+	return chatMessage{}, false
+}
+
+type chatTextParser struct {
+	pattern *regexp.Regexp
+	layouts []string
+	source  string
+	chat    string
+	options Options
+	records []types.URLRecord
+}
+
+func (p *chatTextParser) read(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	var current *chatMessage
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if line == "" && err != nil {
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, utf8BOM)
+		}
+
+		if message, ok := parseChatHeader(line, p.layouts); ok {
+			p.finishMessage(current)
+			message.line = lineNumber
+			current = &message
+		} else if current != nil {
+			current.text += "\n" + line
+		}
+	}
+	p.finishMessage(current)
+	return nil
+}
+
+// finishMessage adds a record for every URL of a completely read message.
+func (p *chatTextParser) finishMessage(message *chatMessage) {
+	if message == nil {
+		return
+	}
+	links, note := textLinks(p.pattern, message.text)
+	var context *types.MessageContext
+	if p.options.attachContext() {
+		context = &types.MessageContext{
+			From:         message.sender,
+			DateUnixtime: strconv.FormatInt(message.date.Unix(), 10),
+			Note:         strings.Join(strings.Fields(note), " "),
+		}
+	}
+	for _, link := range links {
+		p.records = append(p.records, types.URLRecord{
+			Date:    formatLocalDate(message.date),
+			URL:     link.url,
+			Text:    link.text,
+			Chat:    p.chat,
+			Source:  p.source,
+			Line:    message.line,
+			Context: context,
+		})
+	}
+}
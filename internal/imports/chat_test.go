@@ -0,0 +1,109 @@
+package imports_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportWhatsApp(t *testing.T) {
+	mockInput := "\ufeff31/12/2023, 22:15 - Messages and calls are end-to-end encrypted. Tap to learn more.\n" +
+		"31/12/2023, 22:16 - Alice: Happy new year! https://example.com/fireworks\n" +
+		"31/12/2023, 22:17 - ~ Bob: Two links:\n" +
+		"https://example.org/a\n" +
+		"and https://example.org/b.\n" +
+		"01/01/2024, 09:00 - Alice: <Media omitted>\n"
+	tempInputFile := filepath.Join(t.TempDir(), "WhatsApp Chat with Family.txt")
+	if err := os.WriteFile(tempInputFile, []byte(mockInput), 0600); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_whatsapp_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{
+			ID: 1, Date: "2023-12-31T22:16:00Z", URL: "https://example.com/fireworks", Chat: "Family",
+			Source: tempInputFile, Line: 2,
+		},
+		{
+			ID: 2, Date: "2023-12-31T22:17:00Z", URL: "https://example.org/a", Chat: "Family",
+			Source: tempInputFile, Line: 3,
+		},
+		{
+			ID: 3, Date: "2023-12-31T22:17:00Z", URL: "https://example.org/b", Chat: "Family",
+			Source: tempInputFile, Line: 3,
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestChatTextImporterExtract(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  imports.Options
+		expected types.URLRecord
+	}{
+		{
+			name:    "Signal",
+			input:   "[2024-05-01 10:00] Alice: see https://example.com\n",
+			options: imports.Options{MessageContext: true},
+			expected: types.URLRecord{
+				Date: "2024-05-01T10:00:00", URL: "https://example.com", Line: 1,
+				Context: &types.MessageContext{From: "Alice", DateUnixtime: "1714557600", Note: "see"},
+			},
+		},
+		{
+			name:  "WhatsAppDayFirstUnpadded",
+			input: "1/12/2023, 22:15 - Alice: https://example.com\n",
+			expected: types.URLRecord{
+				Date: "2023-12-01T22:15:00", URL: "https://example.com", Line: 1,
+			},
+		},
+		{
+			name:    "WhatsAppIOS",
+			input:   "[12/31/23, 10:15:03\u202fPM] Alice: https://example.com\n",
+			options: imports.Options{MessageContext: true},
+			expected: types.URLRecord{
+				Date: "2023-12-31T22:15:03", URL: "https://example.com", Line: 1,
+				Context: &types.MessageContext{From: "Alice", DateUnixtime: "1704060903"},
+			},
+		},
+		{
+			name:    "CustomLayout",
+			input:   "05/06/24, 10:00 - Alice: https://example.com\n",
+			options: imports.Options{DateLayouts: []string{"01/02/06, 15:04"}},
+			expected: types.URLRecord{
+				Date: "2024-05-06T10:00:00", URL: "https://example.com", Line: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := imports.ChatTextImporter{}.Extract(strings.NewReader(tt.input), tt.options)
+			if err != nil {
+				t.Fatalf("Extract failed: %v", err)
+			}
+			if expected := []types.URLRecord{tt.expected}; !reflect.DeepEqual(result.Records, expected) {
+				t.Errorf("Expected %+v, got %+v", expected, result.Records)
+			}
+		})
+	}
+}
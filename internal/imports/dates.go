@@ -0,0 +1,192 @@
+package imports
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Invalid date modes select what happens to records whose date cannot be
+// parsed. Records without a date are kept in both modes.
+const (
+	// InvalidDatesFlag keeps the record with its date as found in the source
+	// and sets DateInvalid.
+	InvalidDatesFlag = "flag"
+	// InvalidDatesReject drops the record.
+	InvalidDatesReject = "reject"
+)
+
+// localDateLayout is the layout importers use for wall-clock dates without a
+// timezone, such as the dates of Telegram exports. The import pipeline reads
+// them in Options.Timezone.
+const localDateLayout = "2006-01-02T15:04:05"
+
+// InvalidDateModes returns the supported invalid date modes.
+func InvalidDateModes() []string {
+	return []string{InvalidDatesFlag, InvalidDatesReject}
+}
+
+func validateInvalidDateMode(mode string) error {
+	switch mode {
+	case "", InvalidDatesFlag, InvalidDatesReject:
+		return nil
+	default:
+		return fmt.Errorf("unknown invalid date mode %q, expected one of: %s", mode, strings.Join(InvalidDateModes(), ", "))
+	}
+}
+
+// knownDateLayouts are the layouts the import pipeline parses record dates
+// with: the layouts written by importers, common ISO 8601 variants and the
+// formats of feeds.
+func knownDateLayouts() []string {
+	return append([]string{
+		time.RFC3339,
+		localDateLayout,
+		"2006-01-02 15:04:05Z07:00",
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04",
+		"2006-01-02",
+	}, feedDateLayouts()...)
+}
+
+// normalizeDate converts a record date to RFC 3339 in location. Dates without
+// a timezone are taken to be in location. An empty date stays empty; ok is
+// false if the date cannot be parsed.
+func normalizeDate(value string, location *time.Location) (string, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", true
+	}
+	for _, layout := range knownDateLayouts() {
+		if parsed, err := parseInLocation(layout, value, location); err == nil {
+			return parsed.In(location).Format(time.RFC3339), true
+		}
+	}
+	return value, false
+}
+
+// dateBefore reports whether the normalized date a is earlier than b. Dates
+// that cannot be parsed, including empty ones, sort first.
+func dateBefore(a, b string) bool {
+	timeA, errA := time.Parse(time.RFC3339, a)
+	timeB, errB := time.Parse(time.RFC3339, b)
+	switch {
+	case errA != nil && errB != nil:
+		return a < b
+	case errA != nil:
+		return true
+	case errB != nil:
+		return false
+	default:
+		return timeA.Before(timeB)
+	}
+}
+
+// unixDate converts a Unix timestamp in seconds, milliseconds or microseconds
+// to an RFC 3339 date. Zero and negative timestamps yield an empty date, other
+// values that are not a timestamp are kept as found.
+func unixDate(value string) string {
+	value = strings.TrimSpace(value)
+	timestamp, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return value
+	}
+	if timestamp <= 0 {
+		return ""
+	}
+	switch {
+	case timestamp > 1e14:
+		return formatDate(time.UnixMicro(timestamp))
+	case timestamp > 1e11:
+		return formatDate(time.UnixMilli(timestamp))
+	default:
+		return formatDate(time.Unix(timestamp, 0))
+	}
+}
+
+// formatDate converts t to an RFC 3339 date in UTC.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatLocalDate formats the wall-clock time of t without a timezone, for
+// source dates that have none.
+func formatLocalDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(localDateLayout)
+}
+
+// parseDate converts value to a record date using the first of layouts that
+// matches. Dates parsed with a layout without a timezone are kept as local
+// dates. Unparsable values yield an empty date.
+func parseDate(value string, layouts []string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range layouts {
+		parsed, err := parseInLocation(layout, value, time.UTC)
+		if err != nil {
+			continue
+		}
+		if layoutHasZone(layout) {
+			return formatDate(parsed)
+		}
+		return formatLocalDate(parsed)
+	}
+	return ""
+}
+
+// sourceDate converts value to a record date like parseDate. Values that
+// cannot be parsed are kept as found, so that the import pipeline flags or
+// rejects them according to Options.InvalidDates.
+func sourceDate(value string, layouts ...string) string {
+	if date := parseDate(value, layouts); date != "" {
+		return date
+	}
+	return strings.TrimSpace(value)
+}
+
+// parseInLocation parses value like time.ParseInLocation, which reads zone
+// abbreviations unknown to location as UTC. The zones of RFC 822 get their
+// offsets instead, and other unknown abbreviations are an error.
+func parseInLocation(layout, value string, location *time.Location) (time.Time, error) {
+	parsed, err := time.ParseInLocation(layout, value, location)
+	if err != nil || !strings.Contains(layout, "MST") {
+		return parsed, err
+	}
+	name, offset := parsed.Zone()
+	if offset != 0 || parsed.Location() == location || name == "UTC" || name == "GMT" {
+		return parsed, nil
+	}
+	offset, ok := rfc822ZoneOffsets()[name]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown time zone abbreviation %q", name)
+	}
+	return time.Date(parsed.Year(), parsed.Month(), parsed.Day(), parsed.Hour(), parsed.Minute(), parsed.Second(),
+		parsed.Nanosecond(), time.FixedZone(name, offset)), nil
+}
+
+// rfc822ZoneOffsets returns the offsets in seconds of the North American zone
+// abbreviations of RFC 822.
+func rfc822ZoneOffsets() map[string]int {
+	const hour = 60 * 60
+	return map[string]int{
+		"EST": -5 * hour,
+		"EDT": -4 * hour,
+		"CST": -6 * hour,
+		"CDT": -5 * hour,
+		"MST": -7 * hour,
+		"MDT": -6 * hour,
+		"PST": -8 * hour,
+		"PDT": -7 * hour,
+	}
+}
+
+func layoutHasZone(layout string) bool {
+	return strings.Contains(layout, "MST") || strings.Contains(layout, "-07") || strings.Contains(layout, "Z07")
+}
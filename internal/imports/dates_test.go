@@ -0,0 +1,150 @@
+package imports_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportDateNormalization(t *testing.T) {
+	mockInput := `{"messages": [
+		{"date": "2025-05-01T10:00:00", "date_unixtime": "1746093600", "text_entities": [{"type": "link", "text": "http://example.com"}]},
+		{"date": "2025-05-02T08:00:00Z", "text_entities": [{"type": "link", "text": "http://example.org"}]},
+		{"date": "2025-05-03 09:30", "text_entities": [{"type": "link", "text": "http://example.net"}]},
+		{"date": "yesterday", "text_entities": [{"type": "link", "text": "http://example.io"}]},
+		{"text_entities": [{"type": "link", "text": "http://example.dev"}]}
+	]}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_dates_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_dates_output.json")
+	defer os.Remove(tempOutputFile)
+
+	berlin := time.FixedZone("CEST", 2*60*60)
+	tests := []struct {
+		name     string
+		options  imports.Options
+		expected []types.URLRecord
+	}{
+		{
+			name:    "UTC by default, invalid dates flagged",
+			options: imports.Options{},
+			expected: []types.URLRecord{
+				{ID: 1, URL: "http://example.dev"},
+				{ID: 2, Date: "yesterday", DateInvalid: true, URL: "http://example.io"},
+				{ID: 3, Date: "2025-05-01T10:00:00Z", URL: "http://example.com"},
+				{ID: 4, Date: "2025-05-02T08:00:00Z", URL: "http://example.org"},
+				{ID: 5, Date: "2025-05-03T09:30:00Z", URL: "http://example.net"},
+			},
+		},
+		{
+			name:    "timezone",
+			options: imports.Options{Timezone: berlin},
+			expected: []types.URLRecord{
+				{ID: 1, URL: "http://example.dev"},
+				{ID: 2, Date: "yesterday", DateInvalid: true, URL: "http://example.io"},
+				{ID: 3, Date: "2025-05-01T10:00:00+02:00", URL: "http://example.com"},
+				{ID: 4, Date: "2025-05-02T10:00:00+02:00", URL: "http://example.org"},
+				{ID: 5, Date: "2025-05-03T09:30:00+02:00", URL: "http://example.net"},
+			},
+		},
+		{
+			name:    "date_unixtime",
+			options: imports.Options{Timezone: berlin, DateUnixtime: true},
+			expected: []types.URLRecord{
+				{ID: 1, URL: "http://example.dev"},
+				{ID: 2, Date: "yesterday", DateInvalid: true, URL: "http://example.io"},
+				{ID: 3, Date: "2025-05-01T12:00:00+02:00", URL: "http://example.com"},
+				{ID: 4, Date: "2025-05-02T10:00:00+02:00", URL: "http://example.org"},
+				{ID: 5, Date: "2025-05-03T09:30:00+02:00", URL: "http://example.net"},
+			},
+		},
+		{
+			name:    "invalid dates rejected",
+			options: imports.Options{InvalidDates: imports.InvalidDatesReject},
+			expected: []types.URLRecord{
+				{ID: 1, URL: "http://example.dev"},
+				{ID: 2, Date: "2025-05-01T10:00:00Z", URL: "http://example.com"},
+				{ID: 3, Date: "2025-05-02T08:00:00Z", URL: "http://example.org"},
+				{ID: 4, Date: "2025-05-03T09:30:00Z", URL: "http://example.net"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := imports.ProcessImport(tempInputFile, tempOutputFile, tt.options); err != nil {
+				t.Fatalf("ProcessImport failed: %v", err)
+			}
+			var result []types.URLRecord
+			if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+				t.Fatalf("Failed to read output JSON file: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestProcessImportInvalidDateMode(t *testing.T) {
+	mockInput := `{"messages": [{"date": "2025-05-01", "text_entities": [{"type": "link", "text": "http://example.com"}]}]}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_date_mode_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_date_mode_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{InvalidDates: "drop"}); err == nil {
+		t.Error("Expected error for unknown invalid date mode, got nil")
+	}
+}
+
+func TestProcessImportInvalidSourceDates(t *testing.T) {
+	mockInput := `[
+		{"href": "https://example.com/", "time": "2025-05-01T10:00:00Z"},
+		{"href": "https://example.org/", "time": "not a date"}
+	]`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_pinboard_dates_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_pinboard_dates_output.json")
+	defer os.Remove(tempOutputFile)
+
+	tests := []struct {
+		mode     string
+		expected []types.URLRecord
+	}{
+		{
+			mode: imports.InvalidDatesFlag,
+			expected: []types.URLRecord{
+				{ID: 1, Date: "not a date", DateInvalid: true, URL: "https://example.org/"},
+				{ID: 2, Date: "2025-05-01T10:00:00Z", URL: "https://example.com/"},
+			},
+		},
+		{
+			mode:     imports.InvalidDatesReject,
+			expected: []types.URLRecord{{ID: 1, Date: "2025-05-01T10:00:00Z", URL: "https://example.com/"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			options := imports.Options{Format: "pinboard", InvalidDates: tt.mode}
+			if err := imports.ProcessImport(tempInputFile, tempOutputFile, options); err != nil {
+				t.Fatalf("ProcessImport failed: %v", err)
+			}
+			var result []types.URLRecord
+			if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+				t.Fatalf("Failed to read output JSON file: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
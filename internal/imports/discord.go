@@ -0,0 +1,194 @@
+package imports
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"link-builder/internal/types"
+)
+
+// DiscordImporter reads the JSON export of DiscordChatExporter. URLs are
+// collected from the Markdown message content and from link embeds. Records
+// are tagged with the channel name. Like Telegram exports, the messages are
+// decoded one at a time.
+type DiscordImporter struct{}
+
+type discordChannel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type discordMessage struct {
+	ID              string `json:"id"`
+	Timestamp       string `json:"timestamp"`
+	TimestampEdited string `json:"timestampEdited"`
+	Content         string `json:"content"`
+	Author          struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Nickname string `json:"nickname"`
+	} `json:"author"`
+	Embeds []struct {
+		Title       string `json:"title"`
+		URL         string `json:"url"`
+		Description string `json:"description"`
+	} `json:"embeds"`
+}
+
+func (DiscordImporter) Name() string {
+	return "discord"
+}
+
+func (DiscordImporter) Detect(prefix []byte) bool {
+	trimmed := bytes.TrimSpace(prefix)
+	return bytes.HasPrefix(trimmed, []byte("{")) &&
+		bytes.Contains(trimmed, []byte(`"guild"`)) &&
+		bytes.Contains(trimmed, []byte(`"channel"`))
+}
+
+func (DiscordImporter) Extract(r io.Reader, options Options) (Result, error) {
+	stream := jsonStream{decoder: json.NewDecoder(r)}
+	allURLs, err := readDiscordExport(stream, options)
+	if err != nil {
+		return Result{}, fmt.Errorf("parsing Discord export JSON: %w", err)
+	}
+	return Result{Records: allURLs}, nil
+}
+
+// readDiscordExport reads the top-level object. The channel is applied to the
+// records once the whole export has been read, as it may follow the messages.
+func readDiscordExport(stream jsonStream, options Options) ([]types.URLRecord, error) {
+	if err := stream.expectDelim('{'); err != nil {
+		return nil, err
+	}
+	pattern := textLinkPattern()
+	var pending []pendingURL
+	var channel discordChannel
+	for stream.decoder.More() {
+		key, err := stream.readKey()
+		if err != nil {
+			return nil, err
+		}
+		switch key {
+		case "channel":
+			err = stream.decoder.Decode(&channel)
+		case "messages":
+			err = readDiscordMessages(stream, func(message discordMessage) {
+				messageID, _ := strconv.ParseInt(message.ID, 10, 64)
+				for i, record := range discordRecords(pattern, message, options) {
+					pending = append(pending, pendingURL{record: record, messageID: messageID, linkIndex: i + 1})
+				}
+			})
+		default:
+			err = stream.skipValue()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := stream.expectDelim('}'); err != nil {
+		return nil, err
+	}
+
+	channelID, _ := strconv.ParseInt(channel.ID, 10, 64)
+	allURLs := make([]types.URLRecord, 0, len(pending))
+	for _, item := range pending {
+		record := item.record
+		record.Chat = channel.Name
+		record.ChatID = channelID
+		if channel.Name != "" {
+			record.Tags = []string{channel.Name}
+		}
+		if options.IDMode == IDModeMessage && item.messageID != 0 {
+			record.UID = messageUID(channelID, item.messageID, item.linkIndex)
+		}
+		allURLs = append(allURLs, record)
+	}
+	return allURLs, nil
+}
+
+func readDiscordMessages(stream jsonStream, handle func(discordMessage)) error {
+	if err := stream.expectDelim('['); err != nil {
+		return err
+	}
+	for stream.decoder.More() {
+		var message discordMessage
+		if err := stream.decoder.Decode(&message); err != nil {
+			return err
+		}
+		handle(message)
+	}
+	return stream.expectDelim(']')
+}
+
+// discordRecords returns a record for every distinct URL of the message
+// content and its embeds. Embeds of content URLs only add a preview hint.
+func discordRecords(pattern *regexp.Regexp, message discordMessage, options Options) []types.URLRecord {
+	links, note := textLinks(pattern, message.Content)
+	var context *types.MessageContext
+	if options.attachContext() {
+		context = message.context(strings.Join(strings.Fields(note), " "))
+	}
+	date := sourceDate(message.Timestamp, time.RFC3339)
+
+	var records []types.URLRecord
+	index := make(map[string]int)
+	for _, link := range links {
+		if _, exists := index[link.url]; exists {
+			continue
+		}
+		index[link.url] = len(records)
+		records = append(records, types.URLRecord{Date: date, URL: link.url, Text: link.text, Context: context})
+	}
+	for _, embed := range message.Embeds {
+		if embed.URL == "" {
+			continue
+		}
+		var hint *types.PreviewHint
+		if embed.Title != "" || embed.Description != "" {
+			hint = &types.PreviewHint{Title: embed.Title, Description: embed.Description}
+		}
+		if i, exists := index[embed.URL]; exists {
+			if records[i].Hint == nil {
+				records[i].Hint = hint
+			}
+			continue
+		}
+		index[embed.URL] = len(records)
+		records = append(records, types.URLRecord{
+			Date:    date,
+			URL:     embed.URL,
+			Text:    embed.Title,
+			Context: context,
+			Hint:    hint,
+		})
+	}
+	return records
+}
+
+func (m discordMessage) context(note string) *types.MessageContext {
+	messageID, _ := strconv.ParseInt(m.ID, 10, 64)
+	from := m.Author.Nickname
+	if from == "" {
+		from = m.Author.Name
+	}
+	context := &types.MessageContext{
+		MessageID: messageID,
+		From:      from,
+		FromID:    m.Author.ID,
+		Note:      note,
+	}
+	if timestamp, err := time.Parse(time.RFC3339, m.Timestamp); err == nil {
+		context.DateUnixtime = strconv.FormatInt(timestamp.Unix(), 10)
+	}
+	if edited, err := time.Parse(time.RFC3339, m.TimestampEdited); err == nil {
+		context.Edited = formatDate(edited)
+	}
+	return context
+}
@@ -0,0 +1,115 @@
+package imports_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportDiscord(t *testing.T) {
+	mockInput := `{
+  "guild": {"id": "100", "name": "Gophers"},
+  "messages": [
+    {
+      "id": "501",
+      "type": "Default",
+      "timestamp": "2024-05-01T12:00:00.123+02:00",
+      "content": "Look at [the spec](https://go.dev/ref/spec) and <https://go.dev/doc/>",
+      "author": {"id": "7", "name": "ada", "nickname": "Ada"},
+      "embeds": [
+        {"title": "The Go Programming Language Specification", "url": "https://go.dev/ref/spec"},
+        {"title": "Go Playground", "url": "https://go.dev/play/", "description": "Run Go online"}
+      ]
+    },
+    {"id": "502", "timestamp": "2024-05-02T08:00:00+00:00", "content": "no links", "author": {"id": "8"}}
+  ],
+  "channel": {"id": "200", "type": "GuildTextChat", "name": "links"},
+  "messageCount": 2
+}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_discord_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_discord_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{IDMode: imports.IDModeMessage}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{
+			ID:     1,
+			UID:    "200-501-1",
+			Date:   "2024-05-01T10:00:00Z",
+			URL:    "https://go.dev/ref/spec",
+			Text:   "the spec",
+			Chat:   "links",
+			ChatID: 200,
+			Tags:   []string{"links"},
+			Hint:   &types.PreviewHint{Title: "The Go Programming Language Specification"},
+		},
+		{
+			ID:     2,
+			UID:    "200-501-2",
+			Date:   "2024-05-01T10:00:00Z",
+			URL:    "https://go.dev/doc/",
+			Chat:   "links",
+			ChatID: 200,
+			Tags:   []string{"links"},
+		},
+		{
+			ID:     3,
+			UID:    "200-501-3",
+			Date:   "2024-05-01T10:00:00Z",
+			URL:    "https://go.dev/play/",
+			Text:   "Go Playground",
+			Chat:   "links",
+			ChatID: 200,
+			Tags:   []string{"links"},
+			Hint:   &types.PreviewHint{Title: "Go Playground", Description: "Run Go online"},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestDiscordImporterMessageContext(t *testing.T) {
+	mockInput := `{"guild": {}, "channel": {"id": "200", "name": "links"}, "messages": [{
+		"id": "501",
+		"timestamp": "2024-05-01T10:00:00+00:00",
+		"timestampEdited": "2024-05-01T10:05:00+00:00",
+		"content": "see https://example.com/a.",
+		"author": {"id": "7", "name": "ada"}
+	}]}`
+
+	result, err := imports.DiscordImporter{}.Extract(strings.NewReader(mockInput), imports.Options{MessageContext: true})
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(result.Records) != 1 {
+		t.Fatalf("Expected 1 record, got %+v", result.Records)
+	}
+
+	expected := &types.MessageContext{
+		MessageID:    501,
+		From:         "ada",
+		FromID:       "7",
+		DateUnixtime: "1714557600",
+		Edited:       "2024-05-01T10:05:00Z",
+		Note:         "see .",
+	}
+	if record := result.Records[0]; record.URL != "https://example.com/a" || !reflect.DeepEqual(record.Context, expected) {
+		t.Errorf("Expected context %+v for https://example.com/a, got %+v", expected, record)
+	}
+}
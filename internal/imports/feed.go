@@ -0,0 +1,305 @@
+package imports
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"link-builder/internal/types"
+)
+
+// feedDateLayouts are the date formats found in RSS pubDate, Atom and JSON
+// Feed dates, tried in order.
+func feedDateLayouts() []string {
+	return []string{
+		time.RFC3339,
+		time.RFC1123Z,
+		time.RFC1123,
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		"Mon, 2 Jan 2006 15:04:05 MST",
+		"2 Jan 2006 15:04:05 -0700",
+		"Mon, 2 Jan 2006 15:04 -0700",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+	}
+}
+
+// FeedImporter reads RSS 2.0, Atom 1.0 and JSON Feed 1.1 files. Each item
+// yields a record with its link, published date and categories as tags; the
+// item title and summary are kept as a preview hint. Undated items get the
+// date of the feed, if it has one.
+type FeedImporter struct{}
+
+type rssDocument struct {
+	PubDate       string `xml:"channel>pubDate"`
+	LastBuildDate string `xml:"channel>lastBuildDate"`
+	Items         []struct {
+		Title       string   `xml:"title"`
+		Link        string   `xml:"link"`
+		GUID        string   `xml:"guid"`
+		Description string   `xml:"description"`
+		PubDate     string   `xml:"pubDate"`
+		DCDate      string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+		Categories  []string `xml:"category"`
+	} `xml:"channel>item"`
+}
+
+type atomFeed struct {
+	Updated string `xml:"updated"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Published  string `xml:"published"`
+		Updated    string `xml:"updated"`
+		Summary    string `xml:"summary"`
+		Content    string `xml:"content"`
+		Categories []struct {
+			Term string `xml:"term,attr"`
+		} `xml:"category"`
+	} `xml:"entry"`
+}
+
+type jsonFeed struct {
+	Version string `json:"version"`
+	Items   []struct {
+		URL           string   `json:"url"`
+		ExternalURL   string   `json:"external_url"`
+		Title         string   `json:"title"`
+		Summary       string   `json:"summary"`
+		ContentText   string   `json:"content_text"`
+		ContentHTML   string   `json:"content_html"`
+		DatePublished string   `json:"date_published"`
+		DateModified  string   `json:"date_modified"`
+		Tags          []string `json:"tags"`
+	} `json:"items"`
+}
+
+func (FeedImporter) Name() string {
+	return "feed"
+}
+
+func (FeedImporter) Detect(prefix []byte) bool {
+	trimmed := bytes.TrimSpace(prefix)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return bytes.Contains(trimmed, []byte("jsonfeed.org/version/"))
+	}
+	return bytes.HasPrefix(trimmed, []byte("<")) &&
+		(bytes.Contains(trimmed, []byte("<rss")) || bytes.Contains(trimmed, []byte("http://www.w3.org/2005/Atom")))
+}
+
+func (FeedImporter) Extract(r io.Reader, _ Options) (Result, error) {
+	reader := bufio.NewReader(r)
+	first, err := peekNonSpace(reader)
+	if err != nil {
+		return Result{}, fmt.Errorf("reading feed: %w", err)
+	}
+	var records []types.URLRecord
+	if first == '{' {
+		records, err = extractJSONFeed(reader)
+	} else {
+		records, err = extractXMLFeed(reader)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Records: records}, nil
+}
+
+// peekNonSpace returns the first byte after a byte order mark and leading
+// whitespace without consuming it.
+func peekNonSpace(reader *bufio.Reader) (byte, error) {
+	if bom, err := reader.Peek(len(utf8BOM)); err == nil && string(bom) == utf8BOM {
+		if _, err = reader.Discard(len(utf8BOM)); err != nil {
+			return 0, err
+		}
+	}
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b)) {
+			return b, reader.UnreadByte()
+		}
+	}
+}
+
+func extractJSONFeed(r io.Reader) ([]types.URLRecord, error) {
+	var feed jsonFeed
+	if err := json.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("parsing JSON Feed: %w", err)
+	}
+	allURLs := []types.URLRecord{}
+	for _, item := range feed.Items {
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+		summary := item.Summary
+		if summary == "" {
+			summary = item.ContentText
+		}
+		if summary == "" {
+			summary = htmlText(item.ContentHTML)
+		}
+		published := item.DatePublished
+		if published == "" {
+			published = item.DateModified
+		}
+		allURLs = append(allURLs, feedRecord(link, item.Title, summary, published, "", item.Tags))
+	}
+	return allURLs, nil
+}
+
+func extractXMLFeed(r io.Reader) ([]types.URLRecord, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("no rss or feed element found")
+			}
+			return nil, fmt.Errorf("parsing XML feed: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "rss":
+			var document rssDocument
+			if err = decoder.DecodeElement(&document, &start); err != nil {
+				return nil, fmt.Errorf("parsing RSS feed: %w", err)
+			}
+			return rssRecords(document), nil
+		case "feed":
+			var feed atomFeed
+			if err = decoder.DecodeElement(&feed, &start); err != nil {
+				return nil, fmt.Errorf("parsing Atom feed: %w", err)
+			}
+			return atomRecords(feed), nil
+		default:
+			return nil, fmt.Errorf("unsupported feed root element %q", start.Name.Local)
+		}
+	}
+}
+
+func rssRecords(document rssDocument) []types.URLRecord {
+	allURLs := []types.URLRecord{}
+	defaultDate := feedDefaultDate(document.LastBuildDate, document.PubDate)
+	for _, item := range document.Items {
+		link := strings.TrimSpace(item.Link)
+		if link == "" {
+			link = strings.TrimSpace(item.GUID)
+		}
+		published := item.PubDate
+		if published == "" {
+			published = item.DCDate
+		}
+		summary := htmlText(item.Description)
+		allURLs = append(allURLs, feedRecord(link, item.Title, summary, published, defaultDate, item.Categories))
+	}
+	return allURLs
+}
+
+func atomRecords(feed atomFeed) []types.URLRecord {
+	allURLs := []types.URLRecord{}
+	defaultDate := feedDefaultDate(feed.Updated)
+	for _, entry := range feed.Entries {
+		link := ""
+		for _, candidate := range entry.Links {
+			if candidate.Rel == "" || candidate.Rel == "alternate" {
+				link = candidate.Href
+				break
+			}
+		}
+		if link == "" && len(entry.Links) > 0 {
+			link = entry.Links[0].Href
+		}
+		summary := entry.Summary
+		if summary == "" {
+			summary = entry.Content
+		}
+		published := entry.Published
+		if published == "" {
+			published = entry.Updated
+		}
+		categories := make([]string, len(entry.Categories))
+		for i, category := range entry.Categories {
+			categories[i] = category.Term
+		}
+		allURLs = append(allURLs, feedRecord(link, entry.Title, htmlText(summary), published, defaultDate, categories))
+	}
+	return allURLs
+}
+
+// feedRecord returns the record of a feed item. Items without a published
+// date get defaultDate, invalid ones keep their date as found.
+func feedRecord(link, title, summary, published, defaultDate string, categories []string) types.URLRecord {
+	title = strings.TrimSpace(title)
+	summary = strings.TrimSpace(summary)
+	date := sourceDate(published, feedDateLayouts()...)
+	if date == "" {
+		date = defaultDate
+	}
+	record := types.URLRecord{
+		Date: date,
+		URL:  strings.TrimSpace(link),
+		Text: title,
+	}
+	for _, category := range categories {
+		if category = strings.TrimSpace(category); category != "" {
+			record.Tags = appendTag(record.Tags, category)
+		}
+	}
+	if title != "" || summary != "" {
+		record.Hint = &types.PreviewHint{Title: title, Description: summary}
+	}
+	return record
+}
+
+// feedDefaultDate returns the first valid one of the feed level dates as the
+// date of items without one, or an empty date.
+func feedDefaultDate(feedDates ...string) string {
+	for _, value := range feedDates {
+		if date := parseFeedDate(value); date != "" {
+			return date
+		}
+	}
+	return ""
+}
+
+// parseFeedDate converts a feed date to the record date layout. Unparsable
+// dates yield an empty date.
+func parseFeedDate(value string) string {
+	return parseDate(value, feedDateLayouts())
+}
+
+// htmlText returns the text content of an HTML fragment with collapsed
+// whitespace.
+func htmlText(fragment string) string {
+	var text strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(fragment))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(text.String()), " ")
+		case html.TextToken:
+			text.Write(tokenizer.Text())
+			text.WriteByte(' ')
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken, html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package imports_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportFeeds(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name: "RSS",
+			input: `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+	<channel>
+		<title>Shared items</title>
+		<item>
+			<title>First post</title>
+			<link>https://example.com/first</link>
+			<description>&lt;p&gt;A &lt;b&gt;great&lt;/b&gt; read&lt;/p&gt;</description>
+			<pubDate>Wed, 01 May 2024 12:00:00 +0200</pubDate>
+			<category>go</category>
+			<category>web</category>
+		</item>
+		<item>
+			<title>Second post</title>
+			<link>https://example.com/second</link>
+			<pubDate>Thu, 2 May 2024 10:00:00 GMT</pubDate>
+		</item>
+	</channel>
+</rss>`,
+		},
+		{
+			name: "Atom",
+			input: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Shared items</title>
+	<entry>
+		<title>First post</title>
+		<link rel="self" href="https://example.com/feed/1"/>
+		<link rel="alternate" href="https://example.com/first"/>
+		<published>2024-05-01T10:00:00Z</published>
+		<summary type="html">&lt;p&gt;A &lt;b&gt;great&lt;/b&gt; read&lt;/p&gt;</summary>
+		<category term="go"/>
+		<category term="web"/>
+	</entry>
+	<entry>
+		<title>Second post</title>
+		<link href="https://example.com/second"/>
+		<updated>2024-05-02T10:00:00Z</updated>
+	</entry>
+</feed>`,
+		},
+		{
+			name: "JSONFeed",
+			input: `{
+				"version": "https://jsonfeed.org/version/1.1",
+				"title": "Shared items",
+				"items": [
+					{"id": "1", "url": "https://example.com/first", "title": "First post",
+					 "content_html": "<p>A <b>great</b> read</p>", "date_published": "2024-05-01T12:00:00+02:00",
+					 "tags": ["go", "web"]},
+					{"id": "2", "external_url": "https://example.com/second", "title": "Second post",
+					 "date_published": "2024-05-02T10:00:00Z"}
+				]
+			}`,
+		},
+	}
+
+	expected := []types.URLRecord{
+		{
+			ID:   1,
+			Date: "2024-05-01T10:00:00Z",
+			URL:  "https://example.com/first",
+			Text: "First post",
+			Tags: []string{"go", "web"},
+			Hint: &types.PreviewHint{Title: "First post", Description: "A great read"},
+		},
+		{
+			ID:   2,
+			Date: "2024-05-02T10:00:00Z",
+			URL:  "https://example.com/second",
+			Text: "Second post",
+			Hint: &types.PreviewHint{Title: "Second post"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempInputFile := utils.CreateTempFile(t, tt.input, "mock_feed_input")
+			defer os.Remove(tempInputFile)
+
+			tempOutputFile := utils.CreateTempFile(t, "", "mock_feed_output.json")
+			defer os.Remove(tempOutputFile)
+
+			if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{}); err != nil {
+				t.Fatalf("ProcessImport failed: %v", err)
+			}
+
+			var result []types.URLRecord
+			if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+				t.Fatalf("Failed to read output JSON file: %v", err)
+			}
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("Expected %+v, got %+v", expected, result)
+			}
+		})
+	}
+}
+
+func TestFeedImporterErrors(t *testing.T) {
+	inputs := map[string]string{
+		"UnknownRoot": `<?xml version="1.0"?><opml></opml>`,
+		"NoRoot":      `<?xml version="1.0"?>`,
+		"InvalidJSON": `{"version": "https://jsonfeed.org/version/1.1", "items": [`,
+		"Empty":       ``,
+	}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := (imports.FeedImporter{}).Extract(strings.NewReader(input), imports.Options{}); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestFeedImporterZoneAbbreviations(t *testing.T) {
+	tests := map[string]string{
+		"Mon, 02 Jan 2006 15:04:05 EST": "2006-01-02T20:04:05Z",
+		"Sun, 2 Jul 2006 15:04:05 PDT":  "2006-07-02T22:04:05Z",
+		"Mon, 02 Jan 2006 15:04:05 GMT": "2006-01-02T15:04:05Z",
+		"Mon, 02 Jan 2006 15:04:05 UTC": "2006-01-02T15:04:05Z",
+		"Mon, 02 Jan 2006 15:04:05 ABC": "Mon, 02 Jan 2006 15:04:05 ABC",
+	}
+	for pubDate, expected := range tests {
+		input := `<rss version="2.0"><channel><item><link>https://example.com/</link><pubDate>` +
+			pubDate + `</pubDate></item></channel></rss>`
+		result, err := (imports.FeedImporter{}).Extract(strings.NewReader(input), imports.Options{})
+		if err != nil {
+			t.Fatalf("Extract failed: %v", err)
+		}
+		if len(result.Records) != 1 || result.Records[0].Date != expected {
+			t.Errorf("Expected date %q for %q, got %+v", expected, pubDate, result.Records)
+		}
+	}
+}
+
+func TestFeedImporterUndatedItems(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "RSSChannelDate",
+			input: `<rss version="2.0"><channel>
+				<lastBuildDate>Fri, 03 May 2024 08:00:00 GMT</lastBuildDate>
+				<item><link>https://example.com/undated</link></item>
+				<item><link>https://example.com/invalid</link><pubDate>someday</pubDate></item>
+			</channel></rss>`,
+			expected: "2024-05-03T08:00:00Z",
+		},
+		{
+			name: "AtomFeedDate",
+			input: `<feed xmlns="http://www.w3.org/2005/Atom">
+				<updated>2024-05-03T08:00:00Z</updated>
+				<entry><link href="https://example.com/undated"/></entry>
+				<entry><link href="https://example.com/invalid"/><published>someday</published></entry>
+			</feed>`,
+			expected: "2024-05-03T08:00:00Z",
+		},
+		{
+			name: "JSONFeedUndated",
+			input: `{"version": "https://jsonfeed.org/version/1.1", "items": [
+				{"url": "https://example.com/undated"},
+				{"url": "https://example.com/invalid", "date_published": "someday"}
+			]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := (imports.FeedImporter{}).Extract(strings.NewReader(tt.input), imports.Options{})
+			if err != nil {
+				t.Fatalf("Extract failed: %v", err)
+			}
+			if len(result.Records) != 2 {
+				t.Fatalf("Expected 2 records, got %+v", result.Records)
+			}
+			if date := result.Records[0].Date; date != tt.expected {
+				t.Errorf("Expected date %q for an undated item, got %q", tt.expected, date)
+			}
+			// Invalid dates are kept for the import pipeline to flag.
+			if date := result.Records[1].Date; date != "someday" {
+				t.Errorf("Expected the invalid date as found, got %q", date)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package imports
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"link-builder/internal/types"
+)
+
+// Reasons for which RecordFilter drops a record, as counted in the import
+// statistics.
+const (
+	filteredByDate   = "date"
+	filteredBySender = "sender"
+	filteredByText   = "text"
+)
+
+// RecordFilter selects the records of an import by date, sender and message
+// text. It is applied to the records of all sources after their dates are
+// normalized and before their URLs are validated. Zero fields match every
+// record.
+type RecordFilter struct {
+	// Since and Until limit the record dates, both inclusive. Records without
+	// a valid date are dropped if either is set.
+	Since time.Time
+	Until time.Time
+	// From lists the senders to import by name or ID, compared
+	// case-insensitively. Records of sources without senders are dropped if it
+	// is set.
+	From []string
+	// Text matches the URL, the link text or the rest of the message text.
+	Text *regexp.Regexp
+}
+
+// needsContext reports whether the filter uses the message context, which
+// importers then attach even if Options.MessageContext is not set. It is
+// removed from the records after filtering.
+func (f RecordFilter) needsContext() bool {
+	return len(f.From) > 0 || f.Text != nil
+}
+
+// rejects returns why record is filtered out, or an empty string if it is
+// kept. The date of record must be normalized.
+func (f RecordFilter) rejects(record types.URLRecord) string {
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		date, err := time.Parse(time.RFC3339, record.Date)
+		if err != nil || record.DateInvalid ||
+			(!f.Since.IsZero() && date.Before(f.Since)) ||
+			(!f.Until.IsZero() && date.After(f.Until)) {
+			return filteredByDate
+		}
+	}
+	if len(f.From) > 0 && !f.matchesSender(record.Context) {
+		return filteredBySender
+	}
+	if f.Text != nil {
+		note := ""
+		if record.Context != nil {
+			note = record.Context.Note
+		}
+		if !f.Text.MatchString(record.URL) && !f.Text.MatchString(record.Text) && !f.Text.MatchString(note) {
+			return filteredByText
+		}
+	}
+	return ""
+}
+
+func (f RecordFilter) matchesSender(context *types.MessageContext) bool {
+	if context == nil {
+		return false
+	}
+	return slices.ContainsFunc(f.From, func(sender string) bool {
+		return (context.From != "" && strings.EqualFold(sender, context.From)) ||
+			(context.FromID != "" && strings.EqualFold(sender, context.FromID))
+	})
+}
+
+// attachContext reports whether importers attach the message context to
+// records.
+func (o Options) attachContext() bool {
+	return o.MessageContext || o.Filter.needsContext()
+}
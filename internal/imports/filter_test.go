@@ -0,0 +1,136 @@
+package imports_test
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportRecordFilter(t *testing.T) {
+	mockInput := `{"messages": [
+		{"id": 1, "date": "2025-03-31T23:00:00", "from": "Alice", "from_id": "user1", "text_entities": [
+			{"type": "plain", "text": "old news "},
+			{"type": "link", "text": "http://example.com"}
+		]},
+		{"id": 2, "date": "2025-04-01T10:00:00", "from": "Bob", "from_id": "user2", "text_entities": [
+			{"type": "plain", "text": "Go release "},
+			{"type": "link", "text": "http://example.org"}
+		]},
+		{"id": 3, "date": "2025-06-30T18:00:00", "from": "Alice", "from_id": "user1", "text_entities": [
+			{"type": "text_link", "text": "Go tour", "href": "http://example.net"}
+		]},
+		{"id": 4, "date": "2025-07-01T00:00:00", "from": "Carol", "from_id": "user3", "text_entities": [
+			{"type": "link", "text": "http://example.io"}
+		]}
+	]}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_filter_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_filter_output.json")
+	defer os.Remove(tempOutputFile)
+
+	tests := []struct {
+		name     string
+		filter   imports.RecordFilter
+		expected []string
+	}{
+		{
+			name: "date range",
+			filter: imports.RecordFilter{
+				Since: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+				Until: time.Date(2025, 6, 30, 23, 59, 59, 0, time.UTC),
+			},
+			expected: []string{"http://example.org", "http://example.net"},
+		},
+		{
+			name:     "sender by name or ID",
+			filter:   imports.RecordFilter{From: []string{"alice", "user3"}},
+			expected: []string{"http://example.com", "http://example.net", "http://example.io"},
+		},
+		{
+			name:     "message text",
+			filter:   imports.RecordFilter{Text: regexp.MustCompile(`\bGo\b`)},
+			expected: []string{"http://example.org", "http://example.net"},
+		},
+		{
+			name:     "URL",
+			filter:   imports.RecordFilter{Text: regexp.MustCompile(`example\.(com|io)$`)},
+			expected: []string{"http://example.com", "http://example.io"},
+		},
+		{
+			name: "combined",
+			filter: imports.RecordFilter{
+				Since: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+				From:  []string{"Alice"},
+				Text:  regexp.MustCompile(`(?i)go`),
+			},
+			expected: []string{"http://example.net"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{Filter: tt.filter}); err != nil {
+				t.Fatalf("ProcessImport failed: %v", err)
+			}
+			var result []types.URLRecord
+			if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+				t.Fatalf("Failed to read output JSON file: %v", err)
+			}
+			urls := make([]string, len(result))
+			for i, record := range result {
+				urls[i] = record.URL
+				if record.Context != nil {
+					t.Errorf("Expected no context without MessageContext, got %+v", record.Context)
+				}
+			}
+			if !reflect.DeepEqual(urls, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, urls)
+			}
+		})
+	}
+}
+
+func TestProcessImportRecordFilterWithoutContext(t *testing.T) {
+	mockInput := `[
+		{"href": "https://example.com/a", "description": "Go", "time": "2024-05-01T02:00:00Z", "toread": "no"},
+		{"href": "https://example.com/b", "description": "Undated", "toread": "no"}
+	]`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_filter_pinboard.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_filter_pinboard_output.json")
+	defer os.Remove(tempOutputFile)
+
+	// Records without a sender never match a sender filter.
+	options := imports.Options{Filter: imports.RecordFilter{From: []string{"Alice"}}}
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, options); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+	if len(result) != 0 {
+		t.Errorf("Expected no records, got %+v", result)
+	}
+
+	// Undated records are dropped by a date range.
+	options = imports.Options{Filter: imports.RecordFilter{Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, options); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+	result = nil
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+	if len(result) != 1 || result[0].URL != "https://example.com/a" {
+		t.Errorf("Expected only https://example.com/a, got %+v", result)
+	}
+}
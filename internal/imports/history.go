@@ -0,0 +1,237 @@
+package imports
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"link-builder/internal/types"
+)
+
+// sqliteHeader starts every SQLite database file.
+const sqliteHeader = "SQLite format 3\x00"
+
+// sqliteDriver is the database/sql driver registered by history_sqlite.go.
+const sqliteDriver = "sqlite3"
+
+// ErrHistoryUnsupported is returned for browser histories by builds without
+// cgo, which lack the SQLite driver.
+var ErrHistoryUnsupported = errors.New("reading browser histories requires a build with cgo")
+
+// webKitEpochOffset is the number of microseconds between the WebKit epoch,
+// 1601-01-01, used by Chromium and the Unix epoch used by Firefox' PRTime.
+const webKitEpochOffset = 11644473600 * 1_000_000
+
+// Firefox keeps URLs in moz_places and visits in moz_historyvisits, Chromium
+// in urls and visits. Both queries return every URL visited at least once in
+// the date range, with its title, total visit count and first visit in the
+// range.
+const (
+	firefoxHistoryQuery = `
+SELECT p.url, COALESCE(p.title, ''), p.visit_count, MIN(v.visit_date)
+FROM moz_places p JOIN moz_historyvisits v ON v.place_id = p.id
+WHERE v.visit_date >= ? AND v.visit_date <= ?
+GROUP BY p.id
+HAVING p.visit_count >= ?
+ORDER BY MIN(v.visit_date)`
+	chromiumHistoryQuery = `
+SELECT u.url, COALESCE(u.title, ''), u.visit_count, MIN(v.visit_time)
+FROM urls u JOIN visits v ON v.url = u.id
+WHERE v.visit_time >= ? AND v.visit_time <= ?
+GROUP BY u.id
+HAVING u.visit_count >= ?
+ORDER BY MIN(v.visit_time)`
+)
+
+// HistoryFilter selects entries of a browser history. Zero fields match every
+// entry.
+type HistoryFilter struct {
+	// MinVisits is the minimum total number of visits of a URL.
+	MinVisits int
+	// Since and Until limit the visits considered, both inclusive.
+	Since time.Time
+	Until time.Time
+	// Domains lists the hosts to import, including their subdomains.
+	Domains []string
+}
+
+// matchesDomain reports whether rawURL belongs to one of the allowed domains.
+func (f HistoryFilter) matchesDomain(rawURL string) bool {
+	if len(f.Domains) == 0 {
+		return true
+	}
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsedURL.Hostname())
+	for _, domain := range f.Domains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// browserHistory describes the schema and timestamps of a browser's history.
+type browserHistory struct {
+	name  string
+	table string
+	query string
+	// epochOffset is added to Unix microseconds to get the stored timestamp.
+	epochOffset int64
+}
+
+func browserHistories() []browserHistory {
+	return []browserHistory{
+		{name: "Firefox", table: "moz_places", query: firefoxHistoryQuery},
+		{name: "Chromium", table: "urls", query: chromiumHistoryQuery, epochOffset: webKitEpochOffset},
+	}
+}
+
+// HistoryImporter reads the browsing history of Firefox (places.sqlite) and
+// Chromium based browsers (History). The database is copied to a temporary
+// directory first, so that a running browser neither blocks the import nor
+// sees its database opened.
+type HistoryImporter struct{}
+
+func (HistoryImporter) Name() string {
+	return "history"
+}
+
+func (HistoryImporter) Detect(prefix []byte) bool {
+	return bytes.HasPrefix(prefix, []byte(sqliteHeader))
+}
+
+func (importer HistoryImporter) Extract(r io.Reader, options Options) (Result, error) {
+	return importer.extract(r, "", options)
+}
+
+func (importer HistoryImporter) ExtractFile(path string, r io.Reader, options Options) (Result, error) {
+	return importer.extract(r, path, options)
+}
+
+func (HistoryImporter) extract(r io.Reader, path string, options Options) (Result, error) {
+	tempDir, err := os.MkdirTemp("", "link-builder-history-")
+	if err != nil {
+		return Result{}, fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	copyPath, err := copyHistoryDatabase(r, path, tempDir)
+	if err != nil {
+		return Result{}, err
+	}
+	allURLs, err := readHistory(copyPath, options.History)
+	if err != nil {
+		return Result{}, fmt.Errorf("reading browser history: %w", err)
+	}
+	return Result{Records: allURLs}, nil
+}
+
+// copyHistoryDatabase copies the database read from r, and its write-ahead
+// log next to path if there is one, into dir.
+func copyHistoryDatabase(r io.Reader, path, dir string) (string, error) {
+	copyPath := filepath.Join(dir, "history.sqlite")
+	if err := copyToFile(r, copyPath); err != nil {
+		return "", fmt.Errorf("copying history database: %w", err)
+	}
+	if path == "" {
+		return copyPath, nil
+	}
+	wal, err := os.Open(path + "-wal")
+	if errors.Is(err, os.ErrNotExist) {
+		return copyPath, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("opening history write-ahead log: %w", err)
+	}
+	defer wal.Close()
+	if err = copyToFile(wal, copyPath+"-wal"); err != nil {
+		return "", fmt.Errorf("copying history write-ahead log: %w", err)
+	}
+	return copyPath, nil
+}
+
+func copyToFile(r io.Reader, path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func readHistory(path string, filter HistoryFilter) ([]types.URLRecord, error) {
+	if !slices.Contains(sql.Drivers(), sqliteDriver) {
+		return nil, ErrHistoryUnsupported
+	}
+	db, err := sql.Open(sqliteDriver, path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	browser, err := detectBrowser(db)
+	if err != nil {
+		return nil, err
+	}
+	since, until := int64(0), int64(math.MaxInt64)
+	if !filter.Since.IsZero() {
+		since = filter.Since.UnixMicro() + browser.epochOffset
+	}
+	if !filter.Until.IsZero() {
+		until = filter.Until.UnixMicro() + browser.epochOffset
+	}
+	rows, err := db.Query(browser.query, since, until, filter.MinVisits)
+	if err != nil {
+		return nil, fmt.Errorf("querying %s history: %w", browser.name, err)
+	}
+	defer rows.Close()
+
+	allURLs := []types.URLRecord{}
+	for rows.Next() {
+		var (
+			record    types.URLRecord
+			visits    int
+			firstSeen int64
+		)
+		if err = rows.Scan(&record.URL, &record.Text, &visits, &firstSeen); err != nil {
+			return nil, fmt.Errorf("reading %s history: %w", browser.name, err)
+		}
+		if !filter.matchesDomain(record.URL) {
+			continue
+		}
+		record.Date = formatDate(time.UnixMicro(firstSeen - browser.epochOffset))
+		allURLs = append(allURLs, record)
+	}
+	return allURLs, rows.Err()
+}
+
+// detectBrowser tells Firefox and Chromium histories apart by their tables.
+func detectBrowser(db *sql.DB) (browserHistory, error) {
+	for _, browser := range browserHistories() {
+		var count int
+		err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", browser.table).Scan(&count)
+		if err != nil {
+			return browserHistory{}, err
+		}
+		if count > 0 {
+			return browser, nil
+		}
+	}
+	return browserHistory{}, errors.New("database is neither a Firefox nor a Chromium history")
+}
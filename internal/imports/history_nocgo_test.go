@@ -0,0 +1,25 @@
+//go:build !cgo
+
+package imports_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"link-builder/internal/imports"
+)
+
+func TestProcessImportHistoryWithoutCgo(t *testing.T) {
+	tempInputFile := filepath.Join(t.TempDir(), "places.sqlite")
+	if err := os.WriteFile(tempInputFile, []byte("SQLite format 3\x00"), 0600); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+	tempOutputFile := filepath.Join(t.TempDir(), "urls.json")
+
+	err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{})
+	if !errors.Is(err, imports.ErrHistoryUnsupported) {
+		t.Errorf("Expected ErrHistoryUnsupported, got %v", err)
+	}
+}
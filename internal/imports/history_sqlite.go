@@ -0,0 +1,10 @@
+//go:build cgo
+
+package imports
+
+import (
+	// Registers the sqlite3 database/sql driver. The driver needs cgo, so
+	// builds without cgo, such as cross-compiled and static ones, cannot read
+	// browser histories but need no C toolchain.
+	_ "github.com/mattn/go-sqlite3"
+)
@@ -0,0 +1,88 @@
+//go:build cgo
+
+package imports_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+// createHistoryDatabase creates an SQLite database at path by running the
+// given statements.
+func createHistoryDatabase(t *testing.T, path string, statements ...string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	for _, statement := range statements {
+		if _, err = db.Exec(statement); err != nil {
+			t.Fatalf("Failed to run %q: %v", statement, err)
+		}
+	}
+}
+
+func TestProcessImportHistory(t *testing.T) {
+	// 1714557600000000 is 2024-05-01T10:00:00Z in microseconds.
+	firefoxFile := filepath.Join(t.TempDir(), "places.sqlite")
+	createHistoryDatabase(t, firefoxFile,
+		"CREATE TABLE moz_places (id INTEGER PRIMARY KEY, url TEXT, title TEXT, visit_count INTEGER)",
+		"CREATE TABLE moz_historyvisits (id INTEGER PRIMARY KEY, place_id INTEGER, visit_date INTEGER)",
+		"INSERT INTO moz_places VALUES (1, 'https://go.dev/doc/', 'Documentation', 3), (2, 'https://example.com/once', NULL, 1), (3, 'https://blog.go.dev/', NULL, 2)",
+		"INSERT INTO moz_historyvisits (place_id, visit_date) VALUES (1, 1714557600000000), (1, 1714644000000000), (1, 1714730400000000), (2, 1714557600000000), (3, 1714471200000000), (3, 1714734000000000)",
+	)
+	chromiumFile := filepath.Join(t.TempDir(), "History")
+	createHistoryDatabase(t, chromiumFile,
+		"CREATE TABLE urls (id INTEGER PRIMARY KEY, url TEXT, title TEXT, visit_count INTEGER)",
+		"CREATE TABLE visits (id INTEGER PRIMARY KEY, url INTEGER, visit_time INTEGER)",
+		"INSERT INTO urls VALUES (1, 'https://pkg.go.dev/net/url', 'url package', 5), (2, 'https://example.org/', 'Example', 9)",
+		"INSERT INTO visits (url, visit_time) VALUES (1, 13359031200000000), (2, 13359031200000000)",
+	)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_history_output.json")
+	defer os.Remove(tempOutputFile)
+
+	options := imports.Options{History: imports.HistoryFilter{
+		MinVisits: 2,
+		Since:     time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Until:     time.Date(2024, 5, 2, 23, 59, 59, 0, time.UTC),
+		Domains:   []string{"go.dev"},
+	}}
+	if err := imports.ProcessImports([]string{firefoxFile, chromiumFile}, tempOutputFile, options); err != nil {
+		t.Fatalf("ProcessImports failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{ID: 1, Date: "2024-05-01T10:00:00Z", URL: "https://go.dev/doc/", Text: "Documentation"},
+		{ID: 2, Date: "2024-05-01T10:00:00Z", URL: "https://pkg.go.dev/net/url", Text: "url package"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestHistoryImporterUnknownDatabase(t *testing.T) {
+	databaseFile := filepath.Join(t.TempDir(), "other.sqlite")
+	createHistoryDatabase(t, databaseFile, "CREATE TABLE notes (id INTEGER PRIMARY KEY)")
+
+	err := imports.ProcessImport(databaseFile, filepath.Join(t.TempDir(), "urls.json"), imports.Options{})
+	if err == nil {
+		t.Errorf("Expected error for a database without browser history, got nil")
+	}
+}
@@ -0,0 +1,76 @@
+package imports
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"link-builder/internal/types"
+)
+
+// ID modes for the UID field of URL records. The integer ID is assigned in all
+// modes; IDModeCounter leaves the UID empty.
+const (
+	IDModeCounter = "counter"
+	IDModeHash    = "hash"
+	IDModeMessage = "message"
+)
+
+// uidHashLength is the number of hex characters of the SHA-256 URL hash used
+// as UID.
+const uidHashLength = 12
+
+var ErrIDCollision = errors.New("UID collision")
+
+// IDModes returns the supported ID modes.
+func IDModes() []string {
+	return []string{IDModeCounter, IDModeHash, IDModeMessage}
+}
+
+func validateIDMode(mode string) error {
+	switch mode {
+	case "", IDModeCounter, IDModeHash, IDModeMessage:
+		return nil
+	default:
+		return fmt.Errorf("unknown ID mode %q, expected one of: %s", mode, strings.Join(IDModes(), ", "))
+	}
+}
+
+// URLHash returns the short content-derived UID of a normalized URL.
+func URLHash(normalizedURL string) string {
+	sum := sha256.Sum256([]byte(normalizedURL))
+	return hex.EncodeToString(sum[:])[:uidHashLength]
+}
+
+// messageUID returns the UID of the linkIndex-th link of a Telegram message.
+func messageUID(chatID, messageID int64, linkIndex int) string {
+	return fmt.Sprintf("%d-%d-%d", chatID, messageID, linkIndex)
+}
+
+// assignUIDs sets the UID of records[firstNew:] according to mode. Records
+// that already carry a UID, such as Telegram records in IDModeMessage, keep
+// it; all others get the URL hash. Every UID must identify a single URL.
+func assignUIDs(records []types.URLRecord, firstNew int, mode string) error {
+	if mode == "" || mode == IDModeCounter {
+		return nil
+	}
+	for i := firstNew; i < len(records); i++ {
+		if mode == IDModeHash || records[i].UID == "" {
+			records[i].UID = URLHash(records[i].URL)
+		}
+	}
+
+	owners := make(map[string]string, len(records))
+	for _, record := range records {
+		if record.UID == "" {
+			continue
+		}
+		if owner, exists := owners[record.UID]; exists && owner != record.URL {
+			return fmt.Errorf("%w: %s is used by %s and %s", ErrIDCollision, record.UID, owner, record.URL)
+		}
+		owners[record.UID] = record.URL
+	}
+	return nil
+}
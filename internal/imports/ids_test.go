@@ -0,0 +1,87 @@
+package imports_test
+
+import (
+	"os"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestURLHash(t *testing.T) {
+	hash := imports.URLHash("http://example.com")
+	if len(hash) != 12 {
+		t.Errorf("Expected 12 character hash, got %q", hash)
+	}
+	if hash != imports.URLHash("http://example.com") {
+		t.Errorf("Expected hash to be deterministic")
+	}
+	if hash == imports.URLHash("http://example.org") {
+		t.Errorf("Expected different URLs to have different hashes")
+	}
+}
+
+func TestProcessImportIDModes(t *testing.T) {
+	mockInput := `{"name": "Links", "id": 1001, "messages": [
+		{"id": 42, "date": "2025-05-01", "text_entities": [
+			{"type": "link", "text": "http://example.com"},
+			{"type": "text_link", "text": "org", "href": "http://example.org"}
+		]}
+	]}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_id_input.json")
+	defer os.Remove(tempInputFile)
+
+	tests := []struct {
+		mode     string
+		expected []string
+	}{
+		{imports.IDModeCounter, []string{"", ""}},
+		{imports.IDModeHash, []string{imports.URLHash("http://example.com"), imports.URLHash("http://example.org")}},
+		{imports.IDModeMessage, []string{"1001-42-1", "1001-42-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			tempOutputFile := utils.CreateTempFile(t, "", "mock_id_output.json")
+			defer os.Remove(tempOutputFile)
+
+			if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{IDMode: tt.mode}); err != nil {
+				t.Fatalf("ProcessImport failed: %v", err)
+			}
+
+			var result []types.URLRecord
+			if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+				t.Fatalf("Failed to read output JSON file: %v", err)
+			}
+			if len(result) != len(tt.expected) {
+				t.Fatalf("Expected %d records, got %+v", len(tt.expected), result)
+			}
+			for i, record := range result {
+				if record.ID != i+1 || record.UID != tt.expected[i] {
+					t.Errorf("Expected id %d and uid %q, got %+v", i+1, tt.expected[i], record)
+				}
+			}
+		})
+	}
+
+	if err := imports.ProcessImport(tempInputFile, "", imports.Options{IDMode: "random"}); err == nil {
+		t.Errorf("Expected error for unknown ID mode, got nil")
+	}
+}
+
+func TestProcessImportIDCollision(t *testing.T) {
+	existingOutput := `[{"id": 1, "uid": "1001-42-1", "date": "2025-05-01", "url": "http://example.net"}]`
+	tempOutputFile := utils.CreateTempFile(t, existingOutput, "mock_collision_output.json")
+	defer os.Remove(tempOutputFile)
+
+	mockInput := `{"name": "Links", "id": 1001, "messages": [
+		{"id": 42, "date": "2025-05-02", "text_entities": [{"type": "link", "text": "http://example.com"}]}
+	]}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_collision_input.json")
+	defer os.Remove(tempInputFile)
+
+	options := imports.Options{Append: true, IDMode: imports.IDModeMessage}
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, options); err == nil {
+		t.Errorf("Expected UID collision error, got nil")
+	}
+}
@@ -0,0 +1,103 @@
+package imports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"link-builder/internal/types"
+)
+
+// Instapaper folders with a special meaning. All other folder names are
+// user-defined and become tags.
+const (
+	instapaperUnreadFolder  = "Unread"
+	instapaperArchiveFolder = "Archive"
+)
+
+// utf8BOM is the byte order mark spreadsheet tools put in front of CSV files.
+const utf8BOM = "\ufeff"
+
+// InstapaperImporter reads Instapaper's CSV export with the columns URL,
+// Title, Selection, Folder, Timestamp and, in newer exports, Tags.
+type InstapaperImporter struct{}
+
+func (InstapaperImporter) Name() string {
+	return "instapaper"
+}
+
+func (InstapaperImporter) Detect(prefix []byte) bool {
+	return bytes.HasPrefix(bytes.TrimPrefix(prefix, []byte(utf8BOM)), []byte("URL,Title,Selection,Folder"))
+}
+
+func (InstapaperImporter) Extract(r io.Reader, _ Options) (Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return Result{}, fmt.Errorf("reading Instapaper CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimPrefix(strings.TrimSpace(name), utf8BOM)] = i
+	}
+	if _, exists := columns["URL"]; !exists {
+		return Result{}, errors.New("no URL column in Instapaper CSV")
+	}
+	field := func(row []string, name string) string {
+		if i, exists := columns[name]; exists && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	allURLs := []types.URLRecord{}
+	for {
+		row, readErr := reader.Read()
+		if errors.Is(readErr, io.EOF) {
+			return Result{Records: allURLs}, nil
+		}
+		if readErr != nil {
+			return Result{}, fmt.Errorf("reading Instapaper CSV: %w", readErr)
+		}
+		record := types.URLRecord{
+			Date: unixDate(field(row, "Timestamp")),
+			URL:  field(row, "URL"),
+			Text: field(row, "Title"),
+			Tags: instapaperTags(field(row, "Tags")),
+		}
+		switch folder := field(row, "Folder"); folder {
+		case instapaperUnreadFolder:
+			record.Unread = true
+		case instapaperArchiveFolder, "":
+		default:
+			record.Tags = appendTag(record.Tags, folder)
+		}
+		allURLs = append(allURLs, record)
+	}
+}
+
+// instapaperTags parses the Tags column, a JSON array in current exports.
+func instapaperTags(value string) []string {
+	var tags []string
+	if strings.HasPrefix(value, "[") {
+		var parsed []string
+		if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+			for _, tag := range parsed {
+				tags = appendTag(tags, tag)
+			}
+			return tags
+		}
+	}
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = appendTag(tags, tag)
+		}
+	}
+	return tags
+}
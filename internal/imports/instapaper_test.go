@@ -0,0 +1,56 @@
+package imports_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportInstapaper(t *testing.T) {
+	mockInput := "\ufeffURL,Title,Selection,Folder,Timestamp,Tags\n" +
+		`https://example.com/a,"Article, with comma",,Unread,1714521600,"[""go"",""web""]"` + "\n" +
+		`https://example.com/b,Archived,,Archive,1714608000,[]` + "\n" +
+		`https://example.com/c,In folder,Some quote,Research,1714694400,` + "\n"
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_instapaper_input.csv")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_instapaper_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{
+			ID:     1,
+			Date:   "2024-05-01T00:00:00Z",
+			URL:    "https://example.com/a",
+			Text:   "Article, with comma",
+			Tags:   []string{"go", "web"},
+			Unread: true,
+		},
+		{ID: 2, Date: "2024-05-02T00:00:00Z", URL: "https://example.com/b", Text: "Archived"},
+		{ID: 3, Date: "2024-05-03T00:00:00Z", URL: "https://example.com/c", Text: "In folder", Tags: []string{"Research"}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestInstapaperImporterMissingURLColumn(t *testing.T) {
+	input := strings.NewReader("Title,Folder\nExample,Unread\n")
+	if _, err := (imports.InstapaperImporter{}).Extract(input, imports.Options{}); err == nil {
+		t.Errorf("Expected error for CSV without URL column, got nil")
+	}
+}
@@ -0,0 +1,58 @@
+package imports
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonStream reads a JSON document token by token, so that large arrays can be
+// decoded one element at a time.
+type jsonStream struct {
+	decoder *json.Decoder
+}
+
+func (s jsonStream) readKey() (string, error) {
+	token, err := s.decoder.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("expected object key, got %v", token)
+	}
+	return key, nil
+}
+
+func (s jsonStream) expectDelim(expected json.Delim) error {
+	token, err := s.decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("expected %v, got %v", expected, token)
+	}
+	return nil
+}
+
+// skipValue discards the next value without keeping it in memory, which
+// matters for large sections such as contacts or left chats.
+func (s jsonStream) skipValue() error {
+	depth := 0
+	for {
+		token, err := s.decoder.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := token.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -1,90 +1,195 @@
 package imports
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"slices"
+	"sort"
+	"time"
 
+	"link-builder/internal/redirects"
+	"link-builder/internal/types"
 	"link-builder/internal/utils"
 	"link-builder/internal/validation"
 )
 
-func ProcessImport(importInputFilePath, importOutputFilePath string) error {
-	var input struct {
-		Messages []struct {
-			Date         string `json:"date"`
-			TextEntities []struct {
-				Type string `json:"type"`
-				Text string `json:"text"`
-			} `json:"text_entities"`
-		} `json:"messages"`
-	}
-	err := utils.ReadJSONFile(importInputFilePath, &input)
-	if err != nil {
-		return fmt.Errorf("reading and parsing input JSON file: %w", err)
-	}
+// Options configures ProcessImport.
+type Options struct {
+	// Format is the name of the importer to use, or FormatAuto to detect it.
+	Format     string
+	ChatFilter ChatFilter
+	// History selects the entries of browser histories.
+	History HistoryFilter
+	// Filter selects the records of all sources by date, sender and message
+	// text.
+	Filter RecordFilter
+	// Append keeps the records of an existing output file, including their
+	// IDs, and only appends URLs that are not present yet.
+	Append bool
+	// IDMode selects how the UID of new records is derived, see IDModes.
+	IDMode string
+	// MessageContext attaches sender, message ID, timestamps and the message
+	// text to records of message based sources. Filtering by sender or text
+	// attaches it as well.
+	MessageContext bool
+	// TagCaseFold lowercases all tags.
+	TagCaseFold bool
+	// TagAliases replaces tags by their alias, see LoadTagAliases.
+	TagAliases map[string]string
+	// Timezone is the timezone of source dates without one and of all record
+	// dates written. Nil means UTC.
+	Timezone *time.Location
+	// DateUnixtime dates Telegram messages by their date_unixtime instead of
+	// their local-time date.
+	DateUnixtime bool
+	// InvalidDates selects how records with unparsable dates are handled, see
+	// InvalidDateModes. The default is InvalidDatesFlag.
+	InvalidDates string
+	// TrackingRules selects the query parameters stripped from URLs. Nil
+	// means validation.DefaultTrackingRules.
+	TrackingRules *validation.TrackingRules
+	// Canonical enables the optional canonicalizations of the URL form that
+	// identifies duplicates. The URLs written keep their original form.
+	Canonical validation.CanonicalOptions
+	// ReportPath is the file the validation result of every record is
+	// written to, as CSV if it ends in .csv and as JSON otherwise. Empty
+	// disables the report.
+	ReportPath string
+	// Redirects enables the resolution of redirecting URLs, such as those of
+	// URL shorteners, to their final destination. The URL found is kept as
+	// SourceURL. Nil disables resolution.
+	Redirects *redirects.Options
+	// DateLayouts are Go time layouts tried for the message timestamps of chat
+	// text exports before the built-in ones, e.g. to read dates month-first.
+	DateLayouts []string
+}
 
-	allURLs := []struct {
-		ID   int    `json:"id"`
-		Date string `json:"date"`
-		URL  string `json:"url"`
-	}{}
+// ProcessImport imports URLs from importInputFilePath using the built-in
+// importers and writes the valid ones to importOutputFilePath.
+func ProcessImport(importInputFilePath, importOutputFilePath string, options Options) error {
+	return DefaultRegistry().ProcessImport(importInputFilePath, importOutputFilePath, options)
+}
 
-	idCounter := 1
-	for _, message := range input.Messages {
-		for _, entity := range message.TextEntities {
-			if os.Getenv("DEBUG") == "true" {
-				log.Printf("Processing entity: %+v", entity)
-			}
-			if entity.Type == "link" {
-				allURLs = append(allURLs, struct {
-					ID   int    `json:"id"`
-					Date string `json:"date"`
-					URL  string `json:"url"`
-				}{
-					ID:   idCounter,
-					Date: message.Date,
-					URL:  entity.Text,
-				})
-				idCounter++
-			}
+// ProcessImports imports URLs from several inputs using the built-in importers
+// and writes the merged, deduplicated result to importOutputFilePath.
+func ProcessImports(importInputFilePaths []string, importOutputFilePath string, options Options) error {
+	return DefaultRegistry().ProcessImports(importInputFilePaths, importOutputFilePath, options)
+}
+
+// importSource is the extraction result of a single input file.
+type importSource struct {
+	path   string
+	format string
+	result Result
+}
+
+// sourceSummary holds the per-input counts reported in the statistics log.
+type sourceSummary struct {
+	path   string
+	format string
+	read   int
+	kept   int
+}
+
+// statistics collects the counts reported at the end of an import.
+type statistics struct {
+	total        int
+	valid        int
+	invalid      int
+	ignored      int
+	duplicates   int
+	invalidDates int
+	dateMode     string
+	filtered     map[string]int
+	stripped     map[string]int
+	homographs   []string
+	redirected   int
+	resolveHits  int
+	unresolved   int
+	report       []reportEntry
+	appendMode   bool
+	existing     int
+	added        int
+	breakdown    map[string]int
+	sources      []sourceSummary
+}
+
+// mergedRecord is a valid, normalized record along with the index of the
+// source it was taken from.
+type mergedRecord struct {
+	record types.URLRecord
+	source int
+}
+
+// processSources validates and normalizes the records of all sources, merges
+// records that share a normalized URL and writes the result to
+// importOutputFilePath with IDs assigned in date order. In append mode the
+// records of the existing output are kept and only unseen URLs are added.
+func processSources(sources []importSource, importOutputFilePath string, options Options) error {
+	stats := newStatistics(sources, options)
+	prepareRecords(sources, options, &stats)
+
+	var allURLs []string
+	for _, source := range sources {
+		for _, urlObj := range source.result.Records {
+			allURLs = append(allURLs, urlObj.URL)
 		}
 	}
-
 	ignoreRegex, err := utils.CompileIgnoreRegex()
 	if err != nil {
 		ignoreRegex = nil
 	}
+	results := validation.ValidateURLs(allURLs, ignoreRegex)
+	redirected, err := resolveRedirects(sources, results, options, &stats)
+	if err != nil {
+		return err
+	}
 
-	validURLs, ignoredCount := validation.ValidateURLsConcurrently(
-		func() []string {
-			urls := make([]string, len(allURLs))
-			for i, urlObj := range allURLs {
-				urls[i] = urlObj.URL
-			}
-			return urls
-		}(),
-		ignoreRegex,
-	)
-
-	validURLs = validation.ProcessURLs(validURLs)
-	validURLs = validation.EnsureUniqueURLs(validURLs, allURLs)
-
-	// Log statistics
-	totalURLs := len(allURLs)
-	invalidURLs := totalURLs - len(validURLs) - ignoredCount
-	logStatistics(totalURLs, len(validURLs), invalidURLs, ignoredCount)
-
-	filteredURLs := []struct {
-		ID   int    `json:"id"`
-		Date string `json:"date"`
-		URL  string `json:"url"`
-	}{}
-	for _, urlObj := range allURLs {
-		if validURLs[urlObj.URL] {
-			filteredURLs = append(filteredURLs, urlObj)
+	var existingURLs []types.URLRecord
+	if options.Append {
+		if existingURLs, err = loadExistingRecords(importOutputFilePath); err != nil {
+			return err
 		}
 	}
+	present := make(map[string]bool, len(existingURLs))
+	rules := options.trackingRules()
+	nextID := 1
+	for _, urlObj := range existingURLs {
+		present[dedupKey(normalizeKey(urlObj.URL, rules), options.Canonical)] = true
+		nextID = max(nextID, urlObj.ID+1)
+	}
+
+	merged := mergeRecords(sources, results, redirected, present, options, &stats)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return dateBefore(merged[i].record.Date, merged[j].record.Date)
+	})
+
+	filteredURLs := make([]types.URLRecord, 0, len(existingURLs)+len(merged))
+	filteredURLs = append(filteredURLs, existingURLs...)
+	for i, entry := range merged {
+		entry.record.ID = nextID + i
+		filteredURLs = append(filteredURLs, entry.record)
+		stats.sources[entry.source].kept++
+	}
+	stats.added = len(merged)
+
+	if err = assignUIDs(filteredURLs, len(existingURLs), options.IDMode); err != nil {
+		return err
+	}
+
+	logStatistics(stats)
+
+	if options.ReportPath != "" {
+		if err = writeReport(options.ReportPath, stats.report); err != nil {
+			return err
+		}
+		log.Printf("Import report saved to %s", options.ReportPath)
+	}
 
 	err = utils.WriteJSONFile(importOutputFilePath, filteredURLs)
 	if err != nil {
@@ -95,9 +200,350 @@ func ProcessImport(importInputFilePath, importOutputFilePath string) error {
 	return nil
 }
 
-func logStatistics(totalURLs, validURLsCount, invalidURLs, ignoredCount int) {
-	log.Printf("Total URLs read: %d", totalURLs)
-	log.Printf("Valid URLs: %d", validURLsCount)
-	log.Printf("Invalid URLs: %d", invalidURLs)
-	log.Printf("Ignored URLs: %d", ignoredCount)
+func newStatistics(sources []importSource, options Options) statistics {
+	stats := statistics{
+		dateMode:   options.InvalidDates,
+		appendMode: options.Append,
+		breakdown:  make(map[string]int),
+		filtered:   make(map[string]int),
+		stripped:   make(map[string]int),
+	}
+	if stats.dateMode == "" {
+		stats.dateMode = InvalidDatesFlag
+	}
+	for _, source := range sources {
+		stats.total += len(source.result.Records)
+		for key, count := range source.result.Breakdown {
+			stats.breakdown[key] += count
+		}
+		stats.sources = append(stats.sources, sourceSummary{
+			path:   source.path,
+			format: source.format,
+			read:   len(source.result.Records),
+		})
+	}
+	return stats
+}
+
+// prepareRecords normalizes the dates of the source records before they are
+// validated, dropping records with invalid dates in InvalidDatesReject mode
+// and records rejected by the record filter. The message context attached for
+// the filter is removed unless Options.MessageContext is set.
+func prepareRecords(sources []importSource, options Options, stats *statistics) {
+	location := options.Timezone
+	if location == nil {
+		location = time.UTC
+	}
+	for i := range sources {
+		records := sources[i].result.Records[:0]
+		for _, urlObj := range sources[i].result.Records {
+			date, ok := normalizeDate(urlObj.Date, location)
+			if !ok {
+				stats.invalidDates++
+				if stats.dateMode == InvalidDatesReject {
+					stats.addReport(sources[i].path, validation.Result{
+						URL:    urlObj.URL,
+						Status: validation.StatusInvalid,
+						Reason: reasonInvalidDate,
+						Detail: urlObj.Date,
+					})
+					continue
+				}
+				urlObj.DateInvalid = true
+			}
+			urlObj.Date = date
+			if reason := options.Filter.rejects(urlObj); reason != "" {
+				stats.filtered[reason]++
+				stats.addReport(sources[i].path, validation.Result{
+					URL:    urlObj.URL,
+					Status: validation.StatusIgnored,
+					Reason: reasonFilteredBy + reason,
+				})
+				continue
+			}
+			if !options.MessageContext {
+				urlObj.Context = nil
+			}
+			records = append(records, urlObj)
+		}
+		sources[i].result.Records = records
+	}
+}
+
+// mergeRecords normalizes the URLs and tags of the valid records, replaces
+// redirecting URLs by their final destination and merges records whose URLs
+// have the same canonical form. URLs present in the existing output are
+// skipped.
+func mergeRecords(
+	sources []importSource,
+	results map[string]validation.Result,
+	redirected map[string]string,
+	present map[string]bool,
+	options Options,
+	stats *statistics,
+) []mergedRecord {
+	merged := []mergedRecord{}
+	seen := make(map[string]int)
+	rules := options.trackingRules()
+	checked := 0
+	for sourceIndex, source := range sources {
+		for _, urlObj := range source.result.Records {
+			checked++
+			result := results[urlObj.URL]
+			if result.Status != validation.StatusValid {
+				if result.Status == validation.StatusIgnored {
+					stats.ignored++
+				}
+				stats.addReport(source.path, result)
+				continue
+			}
+			normalizedURL, stripped, normalizeErr := validation.NormalizeURLWithRules(urlObj.URL, rules)
+			if normalizeErr != nil {
+				log.Printf("Failed to parse URL %s: %v", urlObj.URL, normalizeErr)
+				stats.addReport(source.path, validation.Result{
+					URL:    urlObj.URL,
+					Status: validation.StatusInvalid,
+					Reason: validation.ReasonParseError,
+					Detail: normalizeErr.Error(),
+				})
+				continue
+			}
+			stats.valid++
+			result = rewriteResult(urlObj.URL, normalizedURL, stripped)
+			if final, ok := redirected[normalizedURL]; ok {
+				stats.redirected++
+				normalizedURL, stripped = followRedirect(final, stripped, rules)
+				result = redirectResult(urlObj.URL, normalizedURL)
+				urlObj.SourceURL = urlObj.URL
+			}
+			for _, key := range stripped {
+				stats.stripped[key]++
+			}
+			if display := validation.DisplayURL(normalizedURL); display != normalizedURL {
+				urlObj.DisplayURL = display
+			}
+			urlObj.URL = normalizedURL
+			urlObj.Tags = normalizeTags(urlObj.Tags, options)
+			key := dedupKey(normalizedURL, options.Canonical)
+			homograph := validation.IsHomograph(hostname(normalizedURL))
+			switch index, exists := seen[key]; {
+			case present[key]:
+				stats.existing++
+				result = duplicateResult(result.URL, validation.ReasonAlreadyPresent, normalizedURL)
+			case exists:
+				stats.duplicates++
+				result = duplicateResult(result.URL, validation.ReasonDuplicate, merged[index].record.URL)
+				mergeDuplicate(&merged[index], mergedRecord{record: urlObj, source: sourceIndex})
+			default:
+				if homograph {
+					stats.homographs = append(stats.homographs, normalizedURL)
+				}
+				seen[key] = len(merged)
+				merged = append(merged, mergedRecord{record: urlObj, source: sourceIndex})
+			}
+			stats.report = append(stats.report, reportEntry{Result: result, Input: source.path, Homograph: homograph})
+		}
+	}
+	stats.invalid = checked - stats.valid - stats.ignored
+	return merged
+}
+
+// rewriteResult returns the result of a valid URL, which is rewritten if
+// normalization changed it.
+func rewriteResult(rawURL, normalizedURL string, stripped []string) validation.Result {
+	switch {
+	case normalizedURL == rawURL:
+		return validation.Result{URL: rawURL, Status: validation.StatusValid}
+	case len(stripped) > 0:
+		return validation.Result{
+			URL:    rawURL,
+			Status: validation.StatusRewritten,
+			Reason: validation.ReasonTrackingParameters,
+			Detail: normalizedURL,
+		}
+	default:
+		return validation.Result{
+			URL:    rawURL,
+			Status: validation.StatusRewritten,
+			Reason: validation.ReasonNormalized,
+			Detail: normalizedURL,
+		}
+	}
+}
+
+// duplicateResult returns the result of a URL that is dropped as duplicate of
+// the URL kept.
+func duplicateResult(rawURL, reason, kept string) validation.Result {
+	return validation.Result{URL: rawURL, Status: validation.StatusDuplicate, Reason: reason, Detail: kept}
+}
+
+// addReport adds the result of a record read from input to the report.
+func (s *statistics) addReport(input string, result validation.Result) {
+	s.report = append(s.report, reportEntry{Result: result, Input: input})
+}
+
+// loadExistingRecords reads the records of a previous import. A missing or
+// empty file yields no records.
+func loadExistingRecords(importOutputFilePath string) ([]types.URLRecord, error) {
+	data, err := os.ReadFile(importOutputFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading existing output file %s: %w", importOutputFilePath, err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var existingURLs []types.URLRecord
+	if err = json.Unmarshal(data, &existingURLs); err != nil {
+		return nil, fmt.Errorf("parsing existing output file %s: %w", importOutputFilePath, err)
+	}
+	log.Printf("Appending to %d existing URLs in %s", len(existingURLs), importOutputFilePath)
+	return existingURLs, nil
+}
+
+// normalizeKey returns the normalized form of rawURL, or rawURL itself if it
+// cannot be parsed.
+func normalizeKey(rawURL string, rules validation.TrackingRules) string {
+	if normalizedURL, _, err := validation.NormalizeURLWithRules(rawURL, rules); err == nil {
+		return normalizedURL
+	}
+	return rawURL
+}
+
+// dedupKey returns the canonical form of normalizedURL that identifies
+// duplicates, or normalizedURL itself if it cannot be parsed.
+func dedupKey(normalizedURL string, options validation.CanonicalOptions) string {
+	if key, err := validation.CanonicalURL(normalizedURL, options); err == nil {
+		return key
+	}
+	return normalizedURL
+}
+
+// hostname returns the host of rawURL without port.
+func hostname(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsedURL.Hostname()
+}
+
+// Validate reports an error if o has an unknown ID or invalid date mode.
+func (o Options) Validate() error {
+	if err := validateIDMode(o.IDMode); err != nil {
+		return err
+	}
+	return validateInvalidDateMode(o.InvalidDates)
+}
+
+func (o Options) trackingRules() validation.TrackingRules {
+	if o.TrackingRules == nil {
+		return validation.DefaultTrackingRules()
+	}
+	return *o.TrackingRules
+}
+
+// mergeDuplicate folds duplicate into existing. The record with the earliest
+// known date wins, tags of both records are combined.
+func mergeDuplicate(existing *mergedRecord, duplicate mergedRecord) {
+	tags := slices.Clone(existing.record.Tags)
+	if duplicate.record.Date != "" &&
+		(existing.record.Date == "" || dateBefore(duplicate.record.Date, existing.record.Date)) {
+		fillMissing(&duplicate.record, existing.record)
+		tags, duplicate.record.Tags = duplicate.record.Tags, tags
+		*existing = duplicate
+	} else {
+		fillMissing(&existing.record, duplicate.record)
+	}
+	for _, tag := range duplicate.record.Tags {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	existing.record.Tags = tags
+}
+
+// fillMissing copies the text, chat, source, context and preview hint of other
+// into record where record has none.
+func fillMissing(record *types.URLRecord, other types.URLRecord) {
+	if record.Text == "" {
+		record.Text = other.Text
+	}
+	if record.Chat == "" && record.ChatID == 0 {
+		record.Chat, record.ChatID = other.Chat, other.ChatID
+	}
+	if record.Source == "" {
+		record.Source, record.Line = other.Source, other.Line
+	}
+	if record.Context == nil {
+		record.Context = other.Context
+	}
+	if record.Hint == nil {
+		record.Hint = other.Hint
+	}
+}
+
+func logStatistics(stats statistics) {
+	log.Printf("Total URLs read: %d", stats.total)
+	entityTypes := make([]string, 0, len(stats.breakdown))
+	for entityType := range stats.breakdown {
+		entityTypes = append(entityTypes, entityType)
+	}
+	sort.Strings(entityTypes)
+	for _, entityType := range entityTypes {
+		log.Printf("  from %s entities: %d", entityType, stats.breakdown[entityType])
+	}
+	log.Printf("Valid URLs: %d", stats.valid)
+	log.Printf("Invalid URLs: %d", stats.invalid)
+	log.Printf("Ignored URLs: %d", stats.ignored)
+	log.Printf("Duplicate URLs: %d", stats.duplicates)
+	for _, reason := range []string{filteredByDate, filteredBySender, filteredByText} {
+		if count := stats.filtered[reason]; count > 0 {
+			log.Printf("Filtered out by %s: %d", reason, count)
+		}
+	}
+	logStrippedParameters(stats.stripped)
+	if stats.redirected > 0 || stats.resolveHits > 0 || stats.unresolved > 0 {
+		log.Printf("Redirected URLs: %d", stats.redirected)
+		log.Printf("Redirect cache hits: %d", stats.resolveHits)
+		log.Printf("Unresolved redirects: %d", stats.unresolved)
+	}
+	if len(stats.homographs) > 0 {
+		log.Printf("Possible homograph hosts: %d", len(stats.homographs))
+		for _, homograph := range stats.homographs {
+			log.Printf("  %s", homograph)
+		}
+	}
+	if stats.invalidDates > 0 {
+		log.Printf("Invalid dates: %d (%s)", stats.invalidDates, stats.dateMode)
+	}
+	if stats.appendMode {
+		log.Printf("New URLs: %d", stats.added)
+		log.Printf("Already present URLs: %d", stats.existing)
+	}
+	if len(stats.sources) > 1 {
+		for _, source := range stats.sources {
+			log.Printf("Source %s (%s): %d URLs read, %d kept", source.path, source.format, source.read, source.kept)
+		}
+	}
+}
+
+// logStrippedParameters reports how often each tracking parameter was stripped.
+func logStrippedParameters(stripped map[string]int) {
+	if len(stripped) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(stripped))
+	total := 0
+	for key, count := range stripped {
+		keys = append(keys, key)
+		total += count
+	}
+	sort.Strings(keys)
+	log.Printf("Stripped tracking parameters: %d", total)
+	for _, key := range keys {
+		log.Printf("  %s: %d", key, stripped[key])
+	}
 }
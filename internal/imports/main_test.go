@@ -2,10 +2,14 @@ package imports_test
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"link-builder/internal/imports"
+	"link-builder/internal/types"
 	"link-builder/internal/utils"
+	"link-builder/internal/validation"
 )
 
 func TestProcessImport(t *testing.T) {
@@ -18,7 +22,7 @@ func TestProcessImport(t *testing.T) {
 	tempOutputFile := utils.CreateTempFile(t, "", "mock_import_output.json")
 	defer os.Remove(tempOutputFile)
 
-	err := imports.ProcessImport(tempInputFile, tempOutputFile)
+	err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{})
 	if err != nil {
 		t.Errorf("ProcessImport failed: %v", err)
 	}
@@ -46,7 +50,7 @@ func TestProcessImportEdgeCases(t *testing.T) {
 	tempOutputFile := utils.CreateTempFile(t, "", "invalid_import_output.json")
 	defer os.Remove(tempOutputFile)
 
-	err := imports.ProcessImport(tempInputFile, tempOutputFile)
+	err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{})
 	if err == nil {
 		t.Errorf("Expected error for invalid JSON, got nil")
 	}
@@ -60,8 +64,444 @@ func TestProcessImportEdgeCases(t *testing.T) {
 	tempInputFile = utils.CreateTempFile(t, "", "empty_import_input.json")
 	defer os.Remove(tempInputFile)
 
-	err = imports.ProcessImport(tempInputFile, tempOutputFile)
+	err = imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{})
 	if err == nil {
 		t.Errorf("Expected error for empty input file, got nil")
 	}
 }
+
+func TestProcessImportTextLinks(t *testing.T) {
+	mockInput := `{"messages": [{"date": "2025-05-01", "text_entities": [
+		{"type": "plain", "text": "Read "},
+		{"type": "text_link", "text": "this article", "href": "https://example.org/article"},
+		{"type": "link", "text": "http://example.com"}
+	]}]}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_text_link_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_text_link_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 URLs, got %+v", result)
+	}
+	if result[0].URL != "https://example.org/article" || result[0].Text != "this article" {
+		t.Errorf("Unexpected text_link record: %+v", result[0])
+	}
+	if result[1].URL != "http://example.com" || result[1].Text != "" {
+		t.Errorf("Unexpected link record: %+v", result[1])
+	}
+}
+
+func TestProcessImportFullAccountExport(t *testing.T) {
+	mockInput := `{"chats": {"about": "", "list": [
+		{"name": "Links", "type": "public_channel", "id": 1001, "messages": [
+			{"date": "2025-05-01", "text_entities": [{"type": "link", "text": "http://example.com"}]}
+		]},
+		{"name": "Family", "type": "private_group", "id": 1002, "messages": [
+			{"date": "2025-05-02", "text_entities": [{"type": "link", "text": "http://example.org"}]}
+		]}
+	]}}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_full_account_input.json")
+	defer os.Remove(tempInputFile)
+
+	tests := []struct {
+		name     string
+		filter   imports.ChatFilter
+		expected []types.URLRecord
+	}{
+		{
+			name:   "AllChats",
+			filter: imports.ChatFilter{},
+			expected: []types.URLRecord{
+				{ID: 1, Date: "2025-05-01T00:00:00Z", URL: "http://example.com", Chat: "Links", ChatID: 1001},
+				{ID: 2, Date: "2025-05-02T00:00:00Z", URL: "http://example.org", Chat: "Family", ChatID: 1002},
+			},
+		},
+		{
+			name:   "ByName",
+			filter: imports.ChatFilter{Names: []string{"links"}},
+			expected: []types.URLRecord{
+				{ID: 1, Date: "2025-05-01T00:00:00Z", URL: "http://example.com", Chat: "Links", ChatID: 1001},
+			},
+		},
+		{
+			name:   "ByIDAndType",
+			filter: imports.ChatFilter{IDs: []int64{1002}, Types: []string{"private_group"}},
+			expected: []types.URLRecord{
+				{ID: 1, Date: "2025-05-02T00:00:00Z", URL: "http://example.org", Chat: "Family", ChatID: 1002},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempOutputFile := utils.CreateTempFile(t, "", "mock_full_account_output.json")
+			defer os.Remove(tempOutputFile)
+
+			if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{ChatFilter: tt.filter}); err != nil {
+				t.Fatalf("ProcessImport failed: %v", err)
+			}
+
+			var result []types.URLRecord
+			if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+				t.Fatalf("Failed to read output JSON file: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestProcessImportsMergesSources(t *testing.T) {
+	telegramInput := `{"messages": [
+		{"date": "2025-05-01T10:00:00", "text_entities": [{"type": "link", "text": "http://example.com"}]},
+		{"date": "2025-05-03T10:00:00", "text_entities": [
+			{"type": "link", "text": "https://example.org/?b=2&sessionid=abc&a=1"},
+			{"type": "link", "text": "http://example.com"}
+		]}
+	]}`
+	bookmarksInput := `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<DL><p>
+    <DT><A HREF="https://example.org/?a=1&amp;b=2" ADD_DATE="1714521600" TAGS="web">Example</A>
+    <DT><A HREF="https://example.net/" ADD_DATE="1746316800">Later</A>
+</DL><p>
+`
+	dir := t.TempDir()
+	telegramFile := filepath.Join(dir, "telegram.json")
+	bookmarksFile := filepath.Join(dir, "bookmarks.html")
+	if err := os.WriteFile(telegramFile, []byte(telegramInput), 0600); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+	if err := os.WriteFile(bookmarksFile, []byte(bookmarksInput), 0600); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+
+	inputPaths, err := imports.ExpandInputPaths([]string{filepath.Join(dir, "*.json"), filepath.Join(dir, "*.html")})
+	if err != nil {
+		t.Fatalf("ExpandInputPaths failed: %v", err)
+	}
+	if len(inputPaths) != 2 {
+		t.Fatalf("Expected 2 input paths, got %v", inputPaths)
+	}
+
+	tempOutputFile := filepath.Join(dir, "urls.json")
+	if err = imports.ProcessImports(inputPaths, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImports failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err = utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{ID: 1, Date: "2024-05-01T00:00:00Z", URL: "https://example.org/?a=1&b=2", Text: "Example", Tags: []string{"web"}},
+		{ID: 2, Date: "2025-05-01T10:00:00Z", URL: "http://example.com"},
+		{ID: 3, Date: "2025-05-04T00:00:00Z", URL: "https://example.net/", Text: "Later"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestExpandInputPathsErrors(t *testing.T) {
+	if _, err := imports.ExpandInputPaths([]string{filepath.Join(t.TempDir(), "*.json")}); err == nil {
+		t.Errorf("Expected error for glob without matches, got nil")
+	}
+	if _, err := imports.ExpandInputPaths([]string{"["}); err == nil {
+		t.Errorf("Expected error for invalid glob, got nil")
+	}
+	if err := imports.ProcessImports(nil, filepath.Join(t.TempDir(), "urls.json"), imports.Options{}); err == nil {
+		t.Errorf("Expected error without inputs, got nil")
+	}
+}
+
+func TestProcessImportAppend(t *testing.T) {
+	existingOutput := `[
+		{"id": 7, "date": "2025-05-02T10:00:00", "url": "http://example.com", "text": "kept"},
+		{"id": 9, "date": "2025-05-03T10:00:00", "url": "http://example.org"}
+	]`
+	tempOutputFile := utils.CreateTempFile(t, existingOutput, "mock_append_output.json")
+	defer os.Remove(tempOutputFile)
+
+	mockInput := `{"messages": [
+		{"date": "2025-04-01T10:00:00", "text_entities": [
+			{"type": "link", "text": "http://example.com"},
+			{"type": "link", "text": "http://example.net"}
+		]},
+		{"date": "2025-05-05T10:00:00", "text_entities": [{"type": "link", "text": "http://example.io"}]}
+	]}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_append_input.json")
+	defer os.Remove(tempInputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{Append: true}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{ID: 7, Date: "2025-05-02T10:00:00", URL: "http://example.com", Text: "kept"},
+		{ID: 9, Date: "2025-05-03T10:00:00", URL: "http://example.org"},
+		{ID: 10, Date: "2025-04-01T10:00:00Z", URL: "http://example.net"},
+		{ID: 11, Date: "2025-05-05T10:00:00Z", URL: "http://example.io"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+
+	// A missing output file is treated as empty.
+	missingOutputFile := filepath.Join(t.TempDir(), "urls.json")
+	if err := imports.ProcessImport(tempInputFile, missingOutputFile, imports.Options{Append: true}); err != nil {
+		t.Fatalf("ProcessImport failed for missing output file: %v", err)
+	}
+	if err := utils.ReadJSONFile(missingOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+	if len(result) != 3 || result[0].ID != 1 {
+		t.Errorf("Unexpected result for missing output file: %+v", result)
+	}
+
+	// An output file that is not a URL list is an error.
+	invalidOutputFile := utils.CreateTempFile(t, `{"not": "a list"}`, "invalid_append_output.json")
+	defer os.Remove(invalidOutputFile)
+	if err := imports.ProcessImport(tempInputFile, invalidOutputFile, imports.Options{Append: true}); err == nil {
+		t.Errorf("Expected error for invalid existing output, got nil")
+	}
+}
+
+func TestProcessImportMessageContext(t *testing.T) {
+	mockInput := `{"messages": [{
+		"id": 42,
+		"date": "2025-05-01T10:00:00",
+		"date_unixtime": "1746093600",
+		"edited": "2025-05-01T11:00:00",
+		"from": "Alice",
+		"from_id": "user123",
+		"forwarded_from": "Go News",
+		"text_entities": [
+			{"type": "plain", "text": "Great read:\n"},
+			{"type": "link", "text": "http://example.com"},
+			{"type": "plain", "text": " and "},
+			{"type": "text_link", "text": "this one", "href": "http://example.org"}
+		]
+	}]}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_context_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_context_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{MessageContext: true}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := &types.MessageContext{
+		MessageID:     42,
+		From:          "Alice",
+		FromID:        "user123",
+		DateUnixtime:  "1746093600",
+		Edited:        "2025-05-01T11:00:00",
+		ForwardedFrom: "Go News",
+		Note:          "Great read: and this one",
+	}
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 URLs, got %+v", result)
+	}
+	for _, record := range result {
+		if !reflect.DeepEqual(record.Context, expected) {
+			t.Errorf("Expected context %+v, got %+v", expected, record.Context)
+		}
+	}
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+	result = nil
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+	for _, record := range result {
+		if record.Context != nil {
+			t.Errorf("Expected no context without MessageContext option, got %+v", record.Context)
+		}
+	}
+}
+
+func TestProcessImportTrackingParameters(t *testing.T) {
+	mockInput := `{"messages": [
+		{"date": "2025-05-01", "text_entities": [{"type": "link", "text": "http://example.com/a?utm_source=feed&id=1"}]},
+		{"date": "2025-05-02", "text_entities": [{"type": "link", "text": "http://example.com/a?id=1&fbclid=abc"}]},
+		{"date": "2025-05-03", "text_entities": [{"type": "link", "text": "http://example.org/?src=mail"}]}
+	]}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_tracking_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_tracking_output.json")
+	defer os.Remove(tempOutputFile)
+
+	rules := validation.DefaultTrackingRules()
+	rules.Domains["example.org"] = []string{"src"}
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{TrackingRules: &rules}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+	expected := []types.URLRecord{
+		{ID: 1, Date: "2025-05-01T00:00:00Z", URL: "http://example.com/a?id=1"},
+		{ID: 2, Date: "2025-05-03T00:00:00Z", URL: "http://example.org/"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestProcessImportsMergeFillsMissingFields(t *testing.T) {
+	notesInput := "---\ndate: 2024-03-01\n---\nhttps://example.com/a\nhttps://example.com/b\n"
+	feedInput := `<rss version="2.0"><channel>
+		<item><title>A</title><link>https://example.com/a</link><pubDate>Sat, 01 Jun 2024 00:00:00 GMT</pubDate></item>
+		<item><title>B</title><link>https://example.com/b</link><pubDate>Mon, 01 Jan 2024 00:00:00 GMT</pubDate></item>
+	</channel></rss>`
+	dir := t.TempDir()
+	notesFile := filepath.Join(dir, "notes.md")
+	feedFile := filepath.Join(dir, "feed.xml")
+	if err := os.WriteFile(notesFile, []byte(notesInput), 0600); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+	if err := os.WriteFile(feedFile, []byte(feedInput), 0600); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+
+	tempOutputFile := filepath.Join(dir, "urls.json")
+	if err := imports.ProcessImports([]string{notesFile, feedFile}, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImports failed: %v", err)
+	}
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	// The earlier record wins either way and takes what it lacks from the other.
+	expected := []types.URLRecord{
+		{
+			ID:     1,
+			Date:   "2024-01-01T00:00:00Z",
+			URL:    "https://example.com/b",
+			Text:   "B",
+			Source: notesFile,
+			Line:   5,
+			Hint:   &types.PreviewHint{Title: "B"},
+		},
+		{
+			ID:     2,
+			Date:   "2024-03-01T00:00:00Z",
+			URL:    "https://example.com/a",
+			Text:   "A",
+			Source: notesFile,
+			Line:   4,
+			Hint:   &types.PreviewHint{Title: "A"},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestProcessImportCanonicalDuplicates(t *testing.T) {
+	mockInput := `{"messages": [
+		{"date": "2025-05-02", "text_entities": [{"type": "link", "text": "HTTP://Example.com:443/a/../b/?"}]},
+		{"date": "2025-05-01", "text_entities": [{"type": "link", "text": "https://example.com/b/"}]},
+		{"date": "2025-05-03", "text_entities": [{"type": "link", "text": "https://www.example.com/b"}]}
+	]}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_canonical_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_canonical_output.json")
+	defer os.Remove(tempOutputFile)
+
+	tests := []struct {
+		name     string
+		options  validation.CanonicalOptions
+		expected []string
+	}{
+		{
+			name:     "default",
+			expected: []string{"https://example.com/b/", "https://www.example.com/b"},
+		},
+		{
+			name:     "fold www and trailing slash",
+			options:  validation.CanonicalOptions{FoldWWW: true, FoldTrailingSlash: true},
+			expected: []string{"https://example.com/b/"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{Canonical: tt.options}); err != nil {
+				t.Fatalf("ProcessImport failed: %v", err)
+			}
+			var result []types.URLRecord
+			if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+				t.Fatalf("Failed to read output JSON file: %v", err)
+			}
+			urls := make([]string, len(result))
+			for i, record := range result {
+				urls[i] = record.URL
+			}
+			if !reflect.DeepEqual(urls, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, urls)
+			}
+		})
+	}
+}
+
+func TestProcessImportInternationalizedDomains(t *testing.T) {
+	mockInput := `{"messages": [
+		{"date": "2025-05-01", "text_entities": [{"type": "link", "text": "https://bücher.de/"}]},
+		{"date": "2025-05-02", "text_entities": [{"type": "link", "text": "https://xn--bcher-kva.de/"}]},
+		{"date": "2025-05-03", "text_entities": [{"type": "link", "text": "https://аpple.com/"}]},
+		{"date": "2025-05-04", "text_entities": [{"type": "link", "text": "https://xn--zz.de/"}]}
+	]}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_idn_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_idn_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+	expected := []types.URLRecord{
+		{ID: 1, Date: "2025-05-01T00:00:00Z", URL: "https://xn--bcher-kva.de/", DisplayURL: "https://bücher.de/"},
+		{ID: 2, Date: "2025-05-03T00:00:00Z", URL: "https://xn--pple-43d.com/"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
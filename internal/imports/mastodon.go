@@ -0,0 +1,208 @@
+package imports
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"link-builder/internal/types"
+)
+
+// MastodonImporter reads the outbox.json of a Mastodon account archive, an
+// ActivityStreams collection of the posts of the account. Links of the post
+// content and media attachments yield records with the post URL as source and
+// the post hashtags as tags. Boosts are skipped.
+type MastodonImporter struct{}
+
+type mastodonActivity struct {
+	Type string `json:"type"`
+	// Object is a note for Create activities and a URL for boosts.
+	Object json.RawMessage `json:"object"`
+}
+
+type mastodonNote struct {
+	ID         string `json:"id"`
+	URL        string `json:"url"`
+	Published  string `json:"published"`
+	Content    string `json:"content"`
+	Attachment []struct {
+		URL string `json:"url"`
+	} `json:"attachment"`
+	Tag []struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	} `json:"tag"`
+}
+
+func (MastodonImporter) Name() string {
+	return "mastodon"
+}
+
+func (MastodonImporter) Detect(prefix []byte) bool {
+	trimmed := bytes.TrimSpace(prefix)
+	return bytes.HasPrefix(trimmed, []byte("{")) &&
+		bytes.Contains(trimmed, []byte(`"orderedItems"`)) &&
+		bytes.Contains(trimmed, []byte("activitystreams"))
+}
+
+func (MastodonImporter) Extract(r io.Reader, _ Options) (Result, error) {
+	stream := jsonStream{decoder: json.NewDecoder(r)}
+	allURLs, err := readMastodonOutbox(stream)
+	if err != nil {
+		return Result{}, fmt.Errorf("parsing Mastodon outbox JSON: %w", err)
+	}
+	return Result{Records: allURLs}, nil
+}
+
+// readMastodonOutbox decodes the orderedItems of the outbox one activity at
+// a time.
+func readMastodonOutbox(stream jsonStream) ([]types.URLRecord, error) {
+	if err := stream.expectDelim('{'); err != nil {
+		return nil, err
+	}
+	allURLs := []types.URLRecord{}
+	for stream.decoder.More() {
+		key, err := stream.readKey()
+		if err != nil {
+			return nil, err
+		}
+		if key != "orderedItems" {
+			if err = stream.skipValue(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err = stream.expectDelim('['); err != nil {
+			return nil, err
+		}
+		for stream.decoder.More() {
+			var activity mastodonActivity
+			if err = stream.decoder.Decode(&activity); err != nil {
+				return nil, err
+			}
+			var note mastodonNote
+			if activity.Type != "Create" || json.Unmarshal(activity.Object, &note) != nil {
+				continue
+			}
+			allURLs = append(allURLs, note.records()...)
+		}
+		if err = stream.expectDelim(']'); err != nil {
+			return nil, err
+		}
+	}
+	return allURLs, stream.expectDelim('}')
+}
+
+// records returns a record for every distinct link and attachment of the note.
+func (n mastodonNote) records() []types.URLRecord {
+	source := n.URL
+	if source == "" {
+		source = n.ID
+	}
+	date := sourceDate(n.Published, time.RFC3339)
+	var hashtags []string
+	for _, tag := range n.Tag {
+		if tag.Type == "Hashtag" {
+			hashtags = appendTag(hashtags, tag.Name)
+		}
+	}
+
+	links := contentLinks(n.Content)
+	for _, attachment := range n.Attachment {
+		links = append(links, textLink{url: attachment.URL})
+	}
+	var records []types.URLRecord
+	seen := make(map[string]bool)
+	for _, link := range links {
+		if link.url == "" || seen[link.url] {
+			continue
+		}
+		seen[link.url] = true
+		records = append(records, types.URLRecord{
+			Date:   date,
+			URL:    link.url,
+			Text:   link.text,
+			Tags:   slices.Clone(hashtags),
+			Source: source,
+		})
+	}
+	return records
+}
+
+// contentLinks returns the links of an HTML post content. Mentions and
+// hashtags, which Mastodon also renders as links, are skipped.
+func contentLinks(content string) []textLink {
+	var links []textLink
+	var current *textLink
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return links
+		case html.StartTagToken:
+			token := tokenizer.Token()
+			if token.DataAtom == atom.A && !isMentionLink(token) {
+				current = &textLink{url: attribute(token, "href")}
+			}
+		case html.EndTagToken:
+			if current != nil && tokenizer.Token().DataAtom == atom.A {
+				current.text = linkLabel(current.text, current.url)
+				links = append(links, *current)
+				current = nil
+			}
+		case html.TextToken:
+			if current != nil {
+				current.text += string(tokenizer.Text())
+			}
+		case html.SelfClosingTagToken, html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
+
+func isMentionLink(token html.Token) bool {
+	class := strings.Fields(attribute(token, "class"))
+	rel := strings.Fields(attribute(token, "rel"))
+	return slices.Contains(class, "mention") || slices.Contains(class, "hashtag") || slices.Contains(rel, "tag")
+}
+
+func attribute(token html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// linkLabel returns the text of a link, or an empty string if the text is
+// just the URL itself: the URL without scheme and www., or a prefix of it
+// followed by an ellipsis, as shortened for display.
+func linkLabel(text, url string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	displayed := withoutScheme(text)
+	shortened := strings.TrimRight(displayed, ".…")
+	bare := withoutScheme(url)
+	switch {
+	case strings.TrimSuffix(displayed, "/") == strings.TrimSuffix(bare, "/"):
+		return ""
+	case shortened != displayed && strings.HasPrefix(bare, shortened):
+		return ""
+	}
+	return text
+}
+
+// withoutScheme returns rawURL without its scheme and a leading www., the way
+// Mastodon and Bluesky display links.
+func withoutScheme(rawURL string) string {
+	if _, rest, found := strings.Cut(rawURL, "://"); found {
+		rawURL = rest
+	}
+	return strings.TrimPrefix(rawURL, "www.")
+}
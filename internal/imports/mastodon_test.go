@@ -0,0 +1,101 @@
+package imports_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportMastodon(t *testing.T) {
+	mockInput := `{
+  "@context": "https://www.w3.org/ns/activitystreams",
+  "id": "outbox.json",
+  "type": "OrderedCollection",
+  "totalItems": 3,
+  "orderedItems": [
+    {
+      "type": "Create",
+      "object": {
+        "id": "https://example.social/users/ada/statuses/1",
+        "url": "https://example.social/@ada/1",
+        "published": "2024-05-01T10:00:00Z",
+        "content": "<p>Hi <span class=\"h-card\"><a href=\"https://example.social/@bob\" class=\"u-url mention\">@<span>bob</span></a></span>, read <a href=\"https://go.dev/blog/\" rel=\"nofollow noopener\"><span class=\"invisible\">https://</span><span class=\"\">go.dev/blog/</span></a> and <a href=\"https://example.com/post\">this post</a> <a href=\"https://example.social/tags/golang\" class=\"mention hashtag\" rel=\"tag\">#<span>golang</span></a></p>",
+        "attachment": [{"type": "Document", "mediaType": "image/png", "url": "https://files.example.social/a.png"}],
+        "tag": [{"type": "Mention", "name": "@bob"}, {"type": "Hashtag", "name": "#golang"}]
+      }
+    },
+    {"type": "Announce", "object": "https://other.social/@carol/2"},
+    {
+      "type": "Create",
+      "object": {
+        "id": "https://example.social/users/ada/statuses/3",
+        "published": "2024-05-02T08:00:00Z",
+        "content": "<p>Again <a href=\"https://go.dev/blog/\">https://go.dev/blog/</a></p>"
+      }
+    }
+  ]
+}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_outbox.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_mastodon_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	source := "https://example.social/@ada/1"
+	expected := []types.URLRecord{
+		{ID: 1, Date: "2024-05-01T10:00:00Z", URL: "https://go.dev/blog/", Tags: []string{"golang"}, Source: source},
+		{ID: 2, Date: "2024-05-01T10:00:00Z", URL: "https://example.com/post", Text: "this post", Tags: []string{"golang"}, Source: source},
+		{ID: 3, Date: "2024-05-01T10:00:00Z", URL: "https://files.example.social/a.png", Tags: []string{"golang"}, Source: source},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestMastodonImporterLinkLabels(t *testing.T) {
+	links := []struct {
+		anchor   string
+		url      string
+		expected string
+	}{
+		{"docs", "https://example.com/docs", "docs"},
+		{"example", "https://example.com/", "example"},
+		{"https://example.com/a", "https://example.com/a", ""},
+		{"example.com/b", "https://www.example.com/b/", ""},
+		{"example.com/very-long-pa…", "https://example.com/very-long-path", ""},
+		{"example.com/other…", "https://example.com/another-path", "example.com/other…"},
+	}
+	content := "<p>"
+	for _, link := range links {
+		content += `<a href=\"` + link.url + `\">` + link.anchor + `</a> `
+	}
+	input := `{"type": "OrderedCollection", "orderedItems": [{"type": "Create", "object": {
+		"url": "https://example.social/@ada/1", "published": "2024-05-01T10:00:00Z", "content": "` + content + `</p>"}}]}`
+
+	result, err := (imports.MastodonImporter{}).Extract(strings.NewReader(input), imports.Options{})
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(result.Records) != len(links) {
+		t.Fatalf("Expected %d records, got %+v", len(links), result.Records)
+	}
+	for i, link := range links {
+		if text := result.Records[i].Text; text != link.expected {
+			t.Errorf("Expected label %q for %q, got %q", link.expected, link.anchor, text)
+		}
+	}
+}
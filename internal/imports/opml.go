@@ -0,0 +1,128 @@
+package imports
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"link-builder/internal/types"
+)
+
+// OPMLImporter reads OPML subscription lists and blogrolls. Every outline with
+// an htmlUrl, or failing that an xmlUrl or url, yields a record. The titles of
+// the enclosing outlines and the category attribute become tags, the outline
+// description becomes a preview hint.
+type OPMLImporter struct{}
+
+func (OPMLImporter) Name() string {
+	return "opml"
+}
+
+func (OPMLImporter) Detect(prefix []byte) bool {
+	trimmed := bytes.TrimSpace(prefix)
+	return bytes.HasPrefix(trimmed, []byte("<")) && bytes.Contains(bytes.ToLower(trimmed), []byte("<opml"))
+}
+
+func (OPMLImporter) Extract(r io.Reader, _ Options) (Result, error) {
+	allURLs, err := extractOPMLURLs(r)
+	if err != nil {
+		return Result{}, fmt.Errorf("parsing OPML: %w", err)
+	}
+	return Result{Records: allURLs}, nil
+}
+
+// extractOPMLURLs walks the outline tree. Outlines without a URL are
+// categories of the outlines nested in them. Outlines without a created date
+// are dated by the document, if it has a date.
+func extractOPMLURLs(r io.Reader) ([]types.URLRecord, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	allURLs := []types.URLRecord{}
+	var (
+		categories  []string
+		inDate      bool
+		dateCreated string
+	)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return allURLs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "dateCreated", "dateModified":
+				inDate = dateCreated == ""
+			case "outline":
+				record, category := opmlOutline(element, categories, dateCreated)
+				if record.URL != "" {
+					allURLs = append(allURLs, record)
+				}
+				categories = append(categories, category)
+			}
+		case xml.EndElement:
+			switch element.Name.Local {
+			case "dateCreated", "dateModified":
+				inDate = false
+			case "outline":
+				if len(categories) > 0 {
+					categories = categories[:len(categories)-1]
+				}
+			}
+		case xml.CharData:
+			if inDate {
+				dateCreated = parseFeedDate(string(element))
+			}
+		}
+	}
+}
+
+// opmlOutline returns the record of an outline and the category it adds to
+// its nested outlines, which is empty for outlines with a URL.
+func opmlOutline(element xml.StartElement, categories []string, defaultDate string) (types.URLRecord, string) {
+	attributes := make(map[string]string, len(element.Attr))
+	for _, attr := range element.Attr {
+		attributes[attr.Name.Local] = attr.Value
+	}
+	title := strings.TrimSpace(attributes["title"])
+	if title == "" {
+		title = strings.TrimSpace(attributes["text"])
+	}
+
+	record := types.URLRecord{Date: defaultDate, Text: title}
+	for _, key := range []string{"htmlUrl", "xmlUrl", "url"} {
+		if record.URL == "" {
+			record.URL = strings.TrimSpace(attributes[key])
+		}
+	}
+	if record.URL == "" {
+		return record, title
+	}
+
+	if created := sourceDate(attributes["created"], feedDateLayouts()...); created != "" {
+		record.Date = created
+	}
+	for _, category := range categories {
+		if category != "" {
+			record.Tags = appendTag(record.Tags, category)
+		}
+	}
+	// Categories are comma-separated slash-delimited paths like "/Tech/Go".
+	for _, path := range strings.Split(attributes["category"], ",") {
+		for _, category := range strings.Split(path, "/") {
+			if category = strings.TrimSpace(category); category != "" {
+				record.Tags = appendTag(record.Tags, category)
+			}
+		}
+	}
+	if description := strings.TrimSpace(attributes["description"]); description != "" {
+		record.Hint = &types.PreviewHint{Title: title, Description: description}
+	}
+	return record, ""
+}
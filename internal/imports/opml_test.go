@@ -0,0 +1,89 @@
+package imports_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportOPML(t *testing.T) {
+	mockInput := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+	<head>
+		<title>Team blogroll</title>
+		<dateCreated>Wed, 01 May 2024 10:00:00 GMT</dateCreated>
+	</head>
+	<body>
+		<outline text="Tech" title="Tech">
+			<outline text="Go">
+				<outline type="rss" text="The Go Blog" htmlUrl="https://go.dev/blog/" xmlUrl="https://go.dev/blog/feed.atom" description="News from the Go team"/>
+			</outline>
+			<outline type="rss" text="Feed only" xmlUrl="https://example.com/feed.xml" category="/News/Daily"/>
+		</outline>
+		<outline type="link" text="Homepage" url="https://example.org/" created="Thu, 02 May 2024 08:00:00 GMT"/>
+		<outline text="Empty folder"/>
+	</body>
+</opml>`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_blogroll.opml")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_opml_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{
+			ID:   1,
+			Date: "2024-05-01T10:00:00Z",
+			URL:  "https://go.dev/blog/",
+			Text: "The Go Blog",
+			Tags: []string{"Tech", "Go"},
+			Hint: &types.PreviewHint{Title: "The Go Blog", Description: "News from the Go team"},
+		},
+		{
+			ID:   2,
+			Date: "2024-05-01T10:00:00Z",
+			URL:  "https://example.com/feed.xml",
+			Text: "Feed only",
+			Tags: []string{"Tech", "News", "Daily"},
+		},
+		{ID: 3, Date: "2024-05-02T08:00:00Z", URL: "https://example.org/", Text: "Homepage"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestOPMLImporterUndatedOutlines(t *testing.T) {
+	input := `<opml version="2.0"><head><title>Blogroll</title></head><body>
+		<outline type="link" text="Undated" url="https://example.com/"/>
+		<outline type="link" text="Invalid" url="https://example.org/" created="someday"/>
+	</body></opml>`
+	result, err := (imports.OPMLImporter{}).Extract(strings.NewReader(input), imports.Options{})
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %+v", result.Records)
+	}
+	if date := result.Records[0].Date; date != "" {
+		t.Errorf("Expected no date, got %q", date)
+	}
+	// Invalid dates are kept for the import pipeline to flag.
+	if date := result.Records[1].Date; date != "someday" {
+		t.Errorf("Expected the invalid date as found, got %q", date)
+	}
+}
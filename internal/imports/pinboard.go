@@ -0,0 +1,61 @@
+package imports
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"link-builder/internal/types"
+)
+
+// PinboardImporter reads Pinboard's JSON export, an array of bookmarks with
+// href, description (the title), time, toread and space-separated tags.
+type PinboardImporter struct{}
+
+type pinboardBookmark struct {
+	Href        string `json:"href"`
+	Description string `json:"description"`
+	Time        string `json:"time"`
+	ToRead      string `json:"toread"`
+	Tags        string `json:"tags"`
+}
+
+func (PinboardImporter) Name() string {
+	return "pinboard"
+}
+
+func (PinboardImporter) Detect(prefix []byte) bool {
+	trimmed := bytes.TrimSpace(prefix)
+	return bytes.HasPrefix(trimmed, []byte("[")) &&
+		bytes.Contains(trimmed, []byte(`"href"`)) &&
+		bytes.Contains(trimmed, []byte(`"toread"`))
+}
+
+func (PinboardImporter) Extract(r io.Reader, _ Options) (Result, error) {
+	decoder := json.NewDecoder(r)
+	if err := (jsonStream{decoder: decoder}).expectDelim('['); err != nil {
+		return Result{}, fmt.Errorf("parsing Pinboard JSON: %w", err)
+	}
+
+	allURLs := []types.URLRecord{}
+	for decoder.More() {
+		var bookmark pinboardBookmark
+		if err := decoder.Decode(&bookmark); err != nil {
+			return Result{}, fmt.Errorf("parsing Pinboard JSON: %w", err)
+		}
+		record := types.URLRecord{
+			URL:    bookmark.Href,
+			Text:   bookmark.Description,
+			Unread: bookmark.ToRead == "yes",
+		}
+		record.Date = sourceDate(bookmark.Time, time.RFC3339)
+		for _, tag := range strings.Fields(bookmark.Tags) {
+			record.Tags = appendTag(record.Tags, tag)
+		}
+		allURLs = append(allURLs, record)
+	}
+	return Result{Records: allURLs}, nil
+}
@@ -0,0 +1,58 @@
+package imports_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportPinboard(t *testing.T) {
+	mockInput := `[
+		{"href": "https://example.com/a", "description": "First", "extended": "", "meta": "abc",
+		 "hash": "def", "time": "2024-05-01T02:00:00Z", "shared": "yes", "toread": "yes", "tags": "go web"},
+		{"href": "https://example.com/b", "description": "Second", "extended": "Notes", "meta": "ghi",
+		 "hash": "jkl", "time": "2024-05-02T00:00:00Z", "shared": "no", "toread": "no", "tags": ""}
+	]`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_pinboard_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_pinboard_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{
+			ID:     1,
+			Date:   "2024-05-01T02:00:00Z",
+			URL:    "https://example.com/a",
+			Text:   "First",
+			Tags:   []string{"go", "web"},
+			Unread: true,
+		},
+		{ID: 2, Date: "2024-05-02T00:00:00Z", URL: "https://example.com/b", Text: "Second"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestPinboardImporterNotAnArray(t *testing.T) {
+	input := `{"href": "https://example.com/a", "toread": "no"}`
+	_, err := (imports.PinboardImporter{}).Extract(strings.NewReader(input), imports.Options{})
+	if err == nil || !strings.Contains(err.Error(), "expected [") {
+		t.Errorf("Expected an error for a JSON object, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package imports
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"link-builder/internal/types"
+)
+
+// pocketUnreadSection is the heading of the list of unread items.
+const pocketUnreadSection = "unread"
+
+// PocketImporter reads Pocket's HTML export, which lists saved items as
+// <a href time_added tags> links under an "Unread" and a "Read Archive"
+// heading.
+type PocketImporter struct{}
+
+func (PocketImporter) Name() string {
+	return "pocket"
+}
+
+func (PocketImporter) Detect(prefix []byte) bool {
+	lower := bytes.ToLower(prefix)
+	return bytes.Contains(lower, []byte("<title>pocket export</title>")) ||
+		bytes.Contains(lower, []byte(" time_added="))
+}
+
+func (PocketImporter) Extract(r io.Reader, _ Options) (Result, error) {
+	allURLs := []types.URLRecord{}
+	tokenizer := html.NewTokenizer(r)
+
+	var (
+		section   string
+		inHeading bool
+		current   *types.URLRecord
+	)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return Result{}, fmt.Errorf("parsing Pocket HTML: %w", err)
+			}
+			return Result{Records: allURLs}, nil
+		case html.StartTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.H1:
+				inHeading = true
+				section = ""
+			case atom.A:
+				record := pocketRecord(token)
+				record.Unread = strings.EqualFold(strings.TrimSpace(section), pocketUnreadSection)
+				allURLs = append(allURLs, record)
+				current = &allURLs[len(allURLs)-1]
+			default:
+			}
+		case html.EndTagToken:
+			switch tokenizer.Token().DataAtom {
+			case atom.H1:
+				inHeading = false
+			case atom.A:
+				if current != nil {
+					current.Text = strings.TrimSpace(current.Text)
+				}
+				current = nil
+			default:
+			}
+		case html.TextToken:
+			if inHeading {
+				section += string(tokenizer.Text())
+			} else if current != nil {
+				current.Text += string(tokenizer.Text())
+			}
+		case html.SelfClosingTagToken, html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
+
+func pocketRecord(token html.Token) types.URLRecord {
+	record := types.URLRecord{}
+	for _, attr := range token.Attr {
+		switch attr.Key {
+		case "href":
+			record.URL = attr.Val
+		case "time_added":
+			record.Date = unixDate(attr.Val)
+		case "tags":
+			for _, tag := range strings.Split(attr.Val, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					record.Tags = appendTag(record.Tags, tag)
+				}
+			}
+		}
+	}
+	return record
+}
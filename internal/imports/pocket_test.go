@@ -0,0 +1,60 @@
+package imports_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportPocket(t *testing.T) {
+	mockInput := `<!DOCTYPE html>
+<html>
+	<head>
+		<meta http-equiv="Content-Type" content="text/html; charset=UTF-8">
+		<title>Pocket Export</title>
+	</head>
+	<body>
+		<h1>Unread</h1>
+		<ul>
+			<li><a href="https://example.com/unread" time_added="1714521600" tags="go,reading">Unread item</a></li>
+		</ul>
+		<h1>Read Archive</h1>
+		<ul>
+			<li><a href="https://example.com/read" time_added="1714608000" tags="">Read item</a></li>
+		</ul>
+	</body>
+</html>`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_pocket_input.html")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_pocket_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{
+			ID:     1,
+			Date:   "2024-05-01T00:00:00Z",
+			URL:    "https://example.com/unread",
+			Text:   "Unread item",
+			Tags:   []string{"go", "reading"},
+			Unread: true,
+		},
+		{ID: 2, Date: "2024-05-02T00:00:00Z", URL: "https://example.com/read", Text: "Read item"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
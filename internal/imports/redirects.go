@@ -0,0 +1,79 @@
+package imports
+
+import (
+	"log"
+
+	"link-builder/internal/redirects"
+	"link-builder/internal/validation"
+)
+
+// resolveRedirects resolves the normalized URLs of the valid records that
+// options.Redirects applies to. It returns the final destination of the URLs
+// that redirect, keyed by normalized URL. URLs that cannot be resolved are
+// kept as they are.
+func resolveRedirects(
+	sources []importSource,
+	results map[string]validation.Result,
+	options Options,
+	stats *statistics,
+) (map[string]string, error) {
+	if options.Redirects == nil {
+		return nil, nil
+	}
+	resolver, err := redirects.NewResolver(*options.Redirects)
+	if err != nil {
+		return nil, err
+	}
+	rules := options.trackingRules()
+	var urls []string
+	for _, source := range sources {
+		for _, urlObj := range source.result.Records {
+			if results[urlObj.URL].Status != validation.StatusValid {
+				continue
+			}
+			if normalizedURL := normalizeKey(urlObj.URL, rules); resolver.Applies(normalizedURL) {
+				urls = append(urls, normalizedURL)
+			}
+		}
+	}
+
+	redirected := make(map[string]string)
+	for rawURL, resolution := range resolver.ResolveAll(urls) {
+		if resolution.Cached {
+			stats.resolveHits++
+		}
+		if resolution.Err != nil {
+			stats.unresolved++
+			log.Printf("Failed to resolve redirects: %v", resolution.Err)
+			continue
+		}
+		if resolution.URL != rawURL {
+			redirected[rawURL] = resolution.URL
+		}
+	}
+	if err = resolver.SaveCache(); err != nil {
+		return nil, err
+	}
+	return redirected, nil
+}
+
+// followRedirect returns the normalized form of final, the destination a URL
+// redirects to, along with the tracking parameters stripped from both.
+func followRedirect(final string, stripped []string, rules validation.TrackingRules) (string, []string) {
+	normalizedFinal, strippedFinal, err := validation.NormalizeURLWithRules(final, rules)
+	if err != nil {
+		return final, stripped
+	}
+	return normalizedFinal, append(stripped, strippedFinal...)
+}
+
+// redirectResult returns the result of a URL that is replaced by the final
+// destination of its redirects.
+func redirectResult(rawURL, final string) validation.Result {
+	return validation.Result{
+		URL:    rawURL,
+		Status: validation.StatusRewritten,
+		Reason: validation.ReasonRedirect,
+		Detail: final,
+	}
+}
@@ -0,0 +1,84 @@
+package imports_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/redirects"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/short", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/article?utm_source=short", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/article", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mockInput := fmt.Sprintf(`{"messages": [
+		{"date": "2025-05-01", "text_entities": [{"type": "link", "text": "%[1]s/short"}]},
+		{"date": "2025-05-02", "text_entities": [{"type": "link", "text": "%[1]s/article"}]},
+		{"date": "2025-05-03", "text_entities": [{"type": "link", "text": "%[1]s/loop"}]}
+	]}`, server.URL)
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_redirects_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_redirects_output.json")
+	defer os.Remove(tempOutputFile)
+
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+	options := imports.Options{
+		ReportPath: reportFile,
+		Redirects:  &redirects.Options{MaxHops: 3, CachePath: filepath.Join(t.TempDir(), "redirects.json")},
+	}
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, options); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{ID: 1, Date: "2025-05-01T00:00:00Z", URL: server.URL + "/article", SourceURL: server.URL + "/short"},
+		{ID: 2, Date: "2025-05-03T00:00:00Z", URL: server.URL + "/loop"},
+	}
+	var records []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &records); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, records)
+	}
+
+	var report []map[string]any
+	if err := utils.ReadJSONFile(reportFile, &report); err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	if len(report) != 3 || report[0]["status"] != "rewritten" || report[0]["reason"] != "redirect" ||
+		report[0]["detail"] != server.URL+"/article" {
+		t.Errorf("Expected the short URL to be reported as redirect, got %+v", report)
+	}
+
+	// Repeat imports take resolved redirects from the cache.
+	server.Close()
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, options); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+	if err := utils.ReadJSONFile(tempOutputFile, &records); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Expected cached redirects to yield %+v, got %+v", expected, records)
+	}
+}
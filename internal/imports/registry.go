@@ -0,0 +1,252 @@
+package imports
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"link-builder/internal/types"
+)
+
+// FormatAuto selects the importer by sniffing the input content.
+const FormatAuto = "auto"
+
+// sniffLength is the number of leading bytes handed to Importer.Detect.
+const sniffLength = 64 * 1024
+
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// Importer extracts URL records from one kind of export file.
+type Importer interface {
+	// Name is the identifier used by the -import-format flag.
+	Name() string
+	// Detect reports whether the leading bytes of an input look like this format.
+	Detect(prefix []byte) bool
+	// Extract reads the whole input and returns the URL records found in it.
+	Extract(r io.Reader, options Options) (Result, error)
+}
+
+// FileImporter is implemented by importers that need the input file itself,
+// e.g. for its path or modification time. The registry calls ExtractFile
+// instead of Extract for them.
+type FileImporter interface {
+	Importer
+	ExtractFile(path string, r io.Reader, options Options) (Result, error)
+}
+
+// Result holds the records extracted by an Importer along with an optional
+// breakdown of where they came from, e.g. per Telegram entity type.
+type Result struct {
+	Records   []types.URLRecord
+	Breakdown map[string]int
+}
+
+// Registry holds the available importers. Detection tries them in the order
+// they were registered, so more specific formats should be registered first.
+type Registry struct {
+	importers []Importer
+}
+
+func NewRegistry(importers ...Importer) *Registry {
+	registry := &Registry{}
+	for _, importer := range importers {
+		registry.Register(importer)
+	}
+	return registry
+}
+
+// DefaultRegistry returns a registry with all built-in importers.
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		HistoryImporter{},
+		DiscordImporter{},
+		TelegramImporter{},
+		MastodonImporter{},
+		BlueskyImporter{},
+		PinboardImporter{},
+		SlackImporter{},
+		OPMLImporter{},
+		FeedImporter{},
+		PocketImporter{},
+		BookmarksImporter{},
+		InstapaperImporter{},
+		ChatTextImporter{},
+		TextImporter{},
+	)
+}
+
+func (r *Registry) Register(importer Importer) {
+	r.importers = append(r.importers, importer)
+}
+
+// Names returns the names of all registered importers.
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.importers))
+	for i, importer := range r.importers {
+		names[i] = importer.Name()
+	}
+	return names
+}
+
+// Lookup returns the importer registered under name.
+func (r *Registry) Lookup(name string) (Importer, error) {
+	for _, importer := range r.importers {
+		if strings.EqualFold(importer.Name(), name) {
+			return importer, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q, expected %s or one of: %s",
+		ErrUnknownFormat, name, FormatAuto, strings.Join(r.Names(), ", "))
+}
+
+// Detect returns the first importer that recognizes prefix.
+func (r *Registry) Detect(prefix []byte) (Importer, error) {
+	for _, importer := range r.importers {
+		if importer.Detect(prefix) {
+			return importer, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unable to detect the format of the input", ErrUnknownFormat)
+}
+
+// ProcessImport extracts URL records from importInputFilePath with the
+// importer selected by options.Format and writes the valid ones to
+// importOutputFilePath.
+func (r *Registry) ProcessImport(importInputFilePath, importOutputFilePath string, options Options) error {
+	return r.ProcessImports([]string{importInputFilePath}, importOutputFilePath, options)
+}
+
+// ProcessImports extracts URL records from every input, selecting the importer
+// per file, and writes the merged valid records to importOutputFilePath.
+func (r *Registry) ProcessImports(importInputFilePaths []string, importOutputFilePath string, options Options) error {
+	if len(importInputFilePaths) == 0 {
+		return errors.New("no input files given")
+	}
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	sources := make([]importSource, 0, len(importInputFilePaths))
+	for _, importInputFilePath := range importInputFilePaths {
+		source, err := r.extractFile(importInputFilePath, options)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source)
+	}
+	return processSources(sources, importOutputFilePath, options)
+}
+
+func (r *Registry) extractFile(importInputFilePath string, options Options) (importSource, error) {
+	file, err := os.Open(importInputFilePath)
+	if err != nil {
+		return importSource{}, fmt.Errorf("opening input file %s: %w", importInputFilePath, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, sniffLength)
+	importer, err := r.selectImporter(reader, importInputFilePath, options.Format)
+	if err != nil {
+		return importSource{}, fmt.Errorf("selecting importer for %s: %w", importInputFilePath, err)
+	}
+	log.Printf("Importing %s as %s", importInputFilePath, importer.Name())
+
+	var result Result
+	if fileImporter, ok := importer.(FileImporter); ok {
+		result, err = fileImporter.ExtractFile(importInputFilePath, reader, options)
+	} else {
+		result, err = importer.Extract(reader, options)
+	}
+	if err != nil {
+		return importSource{}, fmt.Errorf("reading and parsing input file %s: %w", importInputFilePath, err)
+	}
+	return importSource{path: importInputFilePath, format: importer.Name(), result: result}, nil
+}
+
+// selectImporter returns the importer of format, or detects it. Text and
+// Markdown files that no importer recognizes, such as notes without links, are
+// read by the text importer.
+func (r *Registry) selectImporter(reader *bufio.Reader, path, format string) (Importer, error) {
+	if format != "" && format != FormatAuto {
+		return r.Lookup(format)
+	}
+	prefix, err := reader.Peek(sniffLength)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+	importer, err := r.Detect(prefix)
+	if err != nil && isTextFile(path) {
+		if textImporter, lookupErr := r.Lookup(TextImporter{}.Name()); lookupErr == nil {
+			return textImporter, nil
+		}
+	}
+	return importer, err
+}
+
+// ExpandInputPaths resolves glob patterns to the files they match, leaving out
+// the workspace files of Slack exports, and directories to the text, Markdown
+// and Slack daily files below them. Other paths are returned unchanged so that
+// missing files are reported when they are opened.
+func ExpandInputPaths(patterns []string) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, fmt.Errorf("invalid input pattern %q: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no input files match %q", pattern)
+			}
+		}
+		for _, match := range matches {
+			if match != pattern && isSlackWorkspaceFile(match) {
+				continue
+			}
+			files, err := expandDirectory(match)
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				if !seen[file] {
+					seen[file] = true
+					paths = append(paths, file)
+				}
+			}
+		}
+	}
+	return paths, nil
+}
+
+// expandDirectory returns the text, Markdown and Slack daily files below path
+// in lexical order if path is a directory, and path itself otherwise.
+func expandDirectory(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if !entry.IsDir() && (isTextFile(file) || isSlackDailyFile(file)) {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading input directory %s: %w", path, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no text, Markdown or Slack export files in %s", path)
+	}
+	return files, nil
+}
@@ -0,0 +1,124 @@
+package imports_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+type mockImporter struct{}
+
+func (mockImporter) Name() string {
+	return "mock"
+}
+
+func (mockImporter) Detect(prefix []byte) bool {
+	return bytes.HasPrefix(prefix, []byte("MOCK"))
+}
+
+func (mockImporter) Extract(r io.Reader, _ imports.Options) (imports.Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return imports.Result{}, err
+	}
+	url := string(bytes.TrimSpace(bytes.TrimPrefix(data, []byte("MOCK"))))
+	return imports.Result{Records: []types.URLRecord{{ID: 1, Date: "2025-05-01", URL: url}}}, nil
+}
+
+func TestRegistryLookup(t *testing.T) {
+	registry := imports.DefaultRegistry()
+
+	for _, name := range []string{"telegram", "bookmarks", "Telegram"} {
+		if _, err := registry.Lookup(name); err != nil {
+			t.Errorf("Expected importer for %q, got error: %v", name, err)
+		}
+	}
+
+	if _, err := registry.Lookup("unknown"); !errors.Is(err, imports.ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestRegistryDetect(t *testing.T) {
+	registry := imports.DefaultRegistry()
+
+	tests := []struct {
+		name     string
+		prefix   string
+		expected string
+	}{
+		{"TelegramChat", `{"name": "Links", "type": "public_channel", "messages": []}`, "telegram"},
+		{"TelegramAccount", `{"about": "", "personal_information": {}, "chats": {"list": []}}`, "telegram"},
+		{"Bookmarks", "<!DOCTYPE NETSCAPE-Bookmark-file-1>\n<DL><p>", "bookmarks"},
+		{"Pocket", "<!DOCTYPE html><html><head><title>Pocket Export</title>", "pocket"},
+		{"Instapaper", "URL,Title,Selection,Folder,Timestamp\n", "instapaper"},
+		{"RSS", `<?xml version="1.0"?><rss version="2.0"><channel>`, "feed"},
+		{"Atom", `<feed xmlns="http://www.w3.org/2005/Atom">`, "feed"},
+		{"JSONFeed", `{"version": "https://jsonfeed.org/version/1.1", "items": []}`, "feed"},
+		{"Pinboard", `[{"href": "https://example.com", "toread": "no"}]`, "pinboard"},
+		{"Slack", `[{"type": "message", "text": "<https://example.com>", "ts": "1714521600.000100"}]`, "slack"},
+		{"Discord", `{"guild": {"id": "1"}, "channel": {"id": "2"}, "messages": []}`, "discord"},
+		{"Mastodon", `{"@context": "https://www.w3.org/ns/activitystreams", "type": "OrderedCollection", "orderedItems": []}`, "mastodon"},
+		{"BlueskyJSON", `{"records": [{"uri": "at://did:plc:ada/app.bsky.feed.post/3kaaa", "value": {"$type": "app.bsky.feed.post"}}]}`, "bluesky"},
+		{
+			"BlueskyCAR",
+			"\x3a\xa2eroots\x81\xd8\x2a\x58\x25\x00\x01\x71\x12\x20" + strings.Repeat("\x00", 32) + "gversion\x01",
+			"bluesky",
+		},
+		{"WhatsApp", "31/12/2023, 22:16 - Alice: https://example.com\n", "chat"},
+		{"Signal", "[2024-05-01 10:00] Alice: https://example.com\n", "chat"},
+		{"OPML", `<?xml version="1.0"?><opml version="2.0"><head><title>Blogroll</title>`, "opml"},
+		{"Text", "Read https://example.com later\n", "text"},
+		{"TextWithCARWords", "Release version notes – roots of the issue: https://example.com\n", "text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer, err := registry.Detect([]byte(tt.prefix))
+			if err != nil {
+				t.Fatalf("Detect failed: %v", err)
+			}
+			if importer.Name() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, importer.Name())
+			}
+		})
+	}
+
+	if _, err := registry.Detect([]byte("just some text")); !errors.Is(err, imports.ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestRegistryProcessImportCustomImporter(t *testing.T) {
+	registry := imports.NewRegistry(imports.TelegramImporter{})
+	registry.Register(mockImporter{})
+
+	tempInputFile := utils.CreateTempFile(t, "MOCK http://example.com", "mock_custom_input.txt")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_custom_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := registry.ProcessImport(tempInputFile, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+	if len(result) != 1 || result[0].URL != "http://example.com" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	options := imports.Options{Format: "bookmarks"}
+	if err := registry.ProcessImport(tempInputFile, tempOutputFile, options); !errors.Is(err, imports.ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat for unregistered format, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package imports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"link-builder/internal/utils"
+	"link-builder/internal/validation"
+)
+
+// Reasons of report entries for records dropped before URL validation.
+const (
+	reasonInvalidDate = "invalid_date"
+	reasonFilteredBy  = "filtered_by_"
+)
+
+// reportEntry is the validation result of a single record, along with the
+// input file it was read from.
+type reportEntry struct {
+	validation.Result
+	Input string `json:"input,omitempty"`
+	// Homograph marks URLs whose host mixes scripts, see
+	// validation.IsHomograph.
+	Homograph bool `json:"homograph,omitempty"`
+}
+
+// writeReport writes the entries to path, as CSV if its extension is .csv
+// and as JSON otherwise.
+func writeReport(path string, entries []reportEntry) error {
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		if err := utils.WriteJSONFile(path, entries); err != nil {
+			return fmt.Errorf("writing import report: %w", err)
+		}
+		return nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("writing import report: %w", err)
+	}
+	writer := csv.NewWriter(file)
+	_ = writer.Write([]string{"url", "status", "reason", "detail", "input", "homograph"})
+	for _, entry := range entries {
+		_ = writer.Write([]string{
+			entry.URL,
+			string(entry.Status),
+			entry.Reason,
+			entry.Detail,
+			entry.Input,
+			strconv.FormatBool(entry.Homograph),
+		})
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		file.Close()
+		return fmt.Errorf("writing import report: %w", err)
+	}
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("writing import report: %w", err)
+	}
+	return nil
+}
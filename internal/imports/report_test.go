@@ -0,0 +1,103 @@
+package imports_test
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/utils"
+)
+
+const reportInput = `{"messages": [
+	{"date": "2025-05-01", "text_entities": [{"type": "link", "text": "http://example.com/a?utm_source=feed"}]},
+	{"date": "2025-05-02", "text_entities": [{"type": "link", "text": "http://example.com/a"}]},
+	{"date": "2025-05-03", "text_entities": [{"type": "link", "text": "ftp://example.com"}]},
+	{"date": "2025-05-04", "text_entities": [{"type": "link", "text": "http://ignored.com"}]},
+	{"date": "2025-05-05", "text_entities": [{"type": "link", "text": "https://xn--pple-43d.com/"}]},
+	{"date": "2025-05-06", "text_entities": [{"type": "link", "text": "http://example.org"}]},
+	{"date": "someday", "text_entities": [{"type": "link", "text": "http://example.net"}]}
+]}`
+
+func TestProcessImportReportJSON(t *testing.T) {
+	t.Setenv("IMPORT_IGNORE", "^http://ignored\\.com$")
+	tempInputFile := utils.CreateTempFile(t, reportInput, "mock_report_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_report_output.json")
+	defer os.Remove(tempOutputFile)
+
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+	options := imports.Options{ReportPath: reportFile, InvalidDates: imports.InvalidDatesReject}
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, options); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var report []map[string]any
+	if err := utils.ReadJSONFile(reportFile, &report); err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	expected := []map[string]any{
+		{"url": "http://example.net", "status": "invalid", "reason": "invalid_date", "detail": "someday"},
+		{
+			"url":    "http://example.com/a?utm_source=feed",
+			"status": "rewritten",
+			"reason": "tracking_parameters",
+			"detail": "http://example.com/a",
+		},
+		{"url": "http://example.com/a", "status": "duplicate", "reason": "duplicate", "detail": "http://example.com/a"},
+		{"url": "ftp://example.com", "status": "invalid", "reason": "bad_scheme", "detail": `unsupported scheme "ftp"`},
+		{"url": "http://ignored.com", "status": "ignored", "reason": "ignore_rule", "detail": "IMPORT_IGNORE"},
+		{"url": "https://xn--pple-43d.com/", "status": "valid", "homograph": true},
+		{"url": "http://example.org", "status": "valid"},
+	}
+	if len(report) != len(expected) {
+		t.Fatalf("Expected %d report entries, got %+v", len(expected), report)
+	}
+	for i, entry := range report {
+		if entry["input"] != tempInputFile {
+			t.Errorf("Expected input %s, got %v", tempInputFile, entry["input"])
+		}
+		delete(entry, "input")
+		if !reflect.DeepEqual(entry, expected[i]) {
+			t.Errorf("Expected report entry %+v, got %+v", expected[i], entry)
+		}
+	}
+}
+
+func TestProcessImportReportCSV(t *testing.T) {
+	tempInputFile := utils.CreateTempFile(t, reportInput, "mock_report_csv_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_report_csv_output.json")
+	defer os.Remove(tempOutputFile)
+
+	reportFile := filepath.Join(t.TempDir(), "report.csv")
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{ReportPath: reportFile}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	file, err := os.Open(reportFile)
+	if err != nil {
+		t.Fatalf("Failed to open report: %v", err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+	header := []string{"url", "status", "reason", "detail", "input", "homograph"}
+	if !reflect.DeepEqual(rows[0], header) {
+		t.Errorf("Expected header %v, got %v", header, rows[0])
+	}
+	// Without an ignore rule and with flagged dates, every record is reported.
+	if len(rows) != 8 {
+		t.Fatalf("Expected a header and 7 report rows, got %v", rows)
+	}
+	expected := []string{"http://example.net", "valid", "", "", tempInputFile, "false"}
+	if !reflect.DeepEqual(rows[7], expected) {
+		t.Errorf("Expected %v, got %v", expected, rows[7])
+	}
+}
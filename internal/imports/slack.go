@@ -0,0 +1,266 @@
+package imports
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"link-builder/internal/types"
+)
+
+// SlackImporter reads the per-channel daily JSON files of a Slack workspace
+// export, e.g. general/2024-05-01.json. URLs are collected from the message
+// text markup, rich text blocks and link attachments. The channel is taken
+// from the directory name of the file, if given, and becomes the chat and a
+// tag.
+type SlackImporter struct{}
+
+type slackMessage struct {
+	Type        string `json:"type"`
+	User        string `json:"user"`
+	UserName    string `json:"user_name"`
+	UserProfile struct {
+		RealName string `json:"real_name"`
+	} `json:"user_profile"`
+	Text   string `json:"text"`
+	TS     string `json:"ts"`
+	Edited struct {
+		TS string `json:"ts"`
+	} `json:"edited"`
+	Attachments []slackAttachment `json:"attachments"`
+	Blocks      []slackElement    `json:"blocks"`
+}
+
+type slackAttachment struct {
+	FromURL     string `json:"from_url"`
+	OriginalURL string `json:"original_url"`
+	TitleLink   string `json:"title_link"`
+	Title       string `json:"title"`
+	Text        string `json:"text"`
+}
+
+// slackElement is a block or one of its nested rich text elements. Text is
+// only a string for link and text elements, so it is decoded lazily.
+type slackElement struct {
+	Type     string          `json:"type"`
+	URL      string          `json:"url"`
+	Text     json.RawMessage `json:"text"`
+	Elements []slackElement  `json:"elements"`
+}
+
+// slackLinkPattern matches <url> and <url|label> markup of message text.
+// Mentions such as <@U123> or <#C123|general> are not matched.
+func slackLinkPattern() *regexp.Regexp {
+	return regexp.MustCompile(`<((?i:https?)://[^|>]+)(?:\|([^>]*))?>`)
+}
+
+func (SlackImporter) Name() string {
+	return "slack"
+}
+
+func (SlackImporter) Detect(prefix []byte) bool {
+	trimmed := bytes.TrimSpace(prefix)
+	return bytes.HasPrefix(trimmed, []byte("[")) &&
+		bytes.Contains(trimmed, []byte(`"ts"`)) &&
+		bytes.Contains(trimmed, []byte(`"message"`))
+}
+
+func (importer SlackImporter) Extract(r io.Reader, options Options) (Result, error) {
+	return importer.extract(r, "", options)
+}
+
+func (importer SlackImporter) ExtractFile(path string, r io.Reader, options Options) (Result, error) {
+	return importer.extract(r, slackChannel(path), options)
+}
+
+// slackWorkspaceFiles returns the names of the files at the root of a Slack
+// export that describe the workspace rather than hold messages.
+func slackWorkspaceFiles() []string {
+	return []string{
+		"canvases.json",
+		"channels.json",
+		"dms.json",
+		"groups.json",
+		"integration_logs.json",
+		"mpims.json",
+		"users.json",
+	}
+}
+
+// isSlackDailyFile reports whether path is named like the daily message
+// files of a Slack export, e.g. 2024-05-01.json.
+func isSlackDailyFile(path string) bool {
+	name, found := strings.CutSuffix(filepath.Base(path), ".json")
+	if !found {
+		return false
+	}
+	_, err := time.Parse(time.DateOnly, name)
+	return err == nil
+}
+
+// isSlackWorkspaceFile reports whether path is one of the workspace files of
+// a Slack export, which is recognized by its channels.json and users.json.
+func isSlackWorkspaceFile(path string) bool {
+	if !slices.Contains(slackWorkspaceFiles(), filepath.Base(path)) {
+		return false
+	}
+	root := filepath.Dir(path)
+	for _, name := range []string{"channels.json", "users.json"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// slackChannel returns the name of the directory of a daily file, or an empty
+// channel for files given without their directory.
+func slackChannel(path string) string {
+	channel := filepath.Base(filepath.Dir(path))
+	if channel == "." || channel == ".." || channel == string(filepath.Separator) {
+		return ""
+	}
+	return channel
+}
+
+func (SlackImporter) extract(r io.Reader, channel string, options Options) (Result, error) {
+	decoder := json.NewDecoder(r)
+	if err := (jsonStream{decoder: decoder}).expectDelim('['); err != nil {
+		return Result{}, fmt.Errorf("parsing Slack export JSON: %w", err)
+	}
+
+	pattern := slackLinkPattern()
+	allURLs := []types.URLRecord{}
+	for decoder.More() {
+		var message slackMessage
+		if err := decoder.Decode(&message); err != nil {
+			return Result{}, fmt.Errorf("parsing Slack export JSON: %w", err)
+		}
+		if message.Type != "message" {
+			continue
+		}
+		allURLs = append(allURLs, slackRecords(pattern, message, channel, options)...)
+	}
+	return Result{Records: allURLs}, nil
+}
+
+// slackRecords returns a record for every distinct URL of message.
+func slackRecords(pattern *regexp.Regexp, message slackMessage, channel string, options Options) []types.URLRecord {
+	links, note := slackTextLinks(pattern, message.Text)
+	for _, block := range message.Blocks {
+		links = appendSlackBlockLinks(links, block)
+	}
+	for _, attachment := range message.Attachments {
+		links = appendSlackAttachmentLink(links, attachment)
+	}
+
+	var context *types.MessageContext
+	if options.attachContext() {
+		context = message.context(note)
+	}
+	seconds, _, _ := strings.Cut(message.TS, ".")
+
+	var records []types.URLRecord
+	seen := make(map[string]bool)
+	for _, link := range links {
+		if link.url == "" || seen[link.url] {
+			continue
+		}
+		seen[link.url] = true
+		record := types.URLRecord{
+			Date:    unixDate(seconds),
+			URL:     link.url,
+			Text:    link.text,
+			Chat:    channel,
+			Context: context,
+			Hint:    link.hint,
+		}
+		if channel != "" {
+			record.Tags = []string{channel}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// slackLink is a URL of a Slack message along with its label and, for link
+// attachments, the unfurled title and description.
+type slackLink struct {
+	url  string
+	text string
+	hint *types.PreviewHint
+}
+
+// slackTextLinks returns the links of the text markup and the text with the
+// links replaced by their labels.
+func slackTextLinks(pattern *regexp.Regexp, text string) ([]slackLink, string) {
+	var links []slackLink
+	note := pattern.ReplaceAllStringFunc(text, func(markup string) string {
+		match := pattern.FindStringSubmatch(markup)
+		links = append(links, slackLink{url: unescapeSlackText(match[1]), text: unescapeSlackText(match[2])})
+		return match[2]
+	})
+	return links, strings.Join(strings.Fields(unescapeSlackText(note)), " ")
+}
+
+// unescapeSlackText reverts the only escaping Slack applies to message text.
+func unescapeSlackText(text string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">").Replace(text)
+}
+
+func appendSlackBlockLinks(links []slackLink, element slackElement) []slackLink {
+	if element.Type == "link" && element.URL != "" {
+		var text string
+		_ = json.Unmarshal(element.Text, &text)
+		links = append(links, slackLink{url: element.URL, text: text})
+	}
+	for _, child := range element.Elements {
+		links = appendSlackBlockLinks(links, child)
+	}
+	return links
+}
+
+func appendSlackAttachmentLink(links []slackLink, attachment slackAttachment) []slackLink {
+	link := slackLink{url: attachment.FromURL, text: attachment.Title}
+	for _, candidate := range []string{attachment.OriginalURL, attachment.TitleLink} {
+		if link.url == "" {
+			link.url = candidate
+		}
+	}
+	if attachment.Title != "" || attachment.Text != "" {
+		link.hint = &types.PreviewHint{Title: attachment.Title, Description: attachment.Text}
+	}
+	// The unfurl describes a URL of the message text, so it only adds a hint.
+	for i := range links {
+		if links[i].url == link.url {
+			if links[i].hint == nil {
+				links[i].hint = link.hint
+			}
+			return links
+		}
+	}
+	return append(links, link)
+}
+
+func (m slackMessage) context(note string) *types.MessageContext {
+	from := m.UserProfile.RealName
+	if from == "" {
+		from = m.UserName
+	}
+	seconds, _, _ := strings.Cut(m.TS, ".")
+	editedSeconds, _, _ := strings.Cut(m.Edited.TS, ".")
+	return &types.MessageContext{
+		From:         from,
+		FromID:       m.User,
+		DateUnixtime: seconds,
+		Edited:       unixDate(editedSeconds),
+		Note:         note,
+	}
+}
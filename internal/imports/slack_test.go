@@ -0,0 +1,161 @@
+package imports_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportSlack(t *testing.T) {
+	mockInput := `[
+    {
+        "type": "message",
+        "user": "U01",
+        "user_profile": {"real_name": "Ada"},
+        "text": "Read <https://example.com/a?x=1&amp;y=2|this post> and <@U02>",
+        "ts": "1714521600.000100",
+        "blocks": [{"type": "rich_text", "elements": [{"type": "rich_text_section", "elements": [
+            {"type": "text", "text": "Read "},
+            {"type": "link", "url": "https://example.com/a?x=1&y=2", "text": "this post"}
+        ]}]}],
+        "attachments": [{"from_url": "https://example.com/a?x=1&y=2", "title": "A", "text": "About A"}]
+    },
+    {
+        "type": "message",
+        "text": "shared",
+        "ts": "1714608000.000200",
+        "attachments": [{"original_url": "https://example.org/b", "title": "B"}]
+    },
+    {"type": "channel_join", "text": "<https://example.net/ignored>", "ts": "1714608000.000300"}
+]`
+	dir := filepath.Join(t.TempDir(), "general")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatalf("Failed to create channel directory: %v", err)
+	}
+	tempInputFile := filepath.Join(dir, "2024-05-01.json")
+	if err := os.WriteFile(tempInputFile, []byte(mockInput), 0600); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_slack_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := imports.ProcessImport(tempInputFile, tempOutputFile, imports.Options{MessageContext: true}); err != nil {
+		t.Fatalf("ProcessImport failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{
+			ID:   1,
+			Date: "2024-05-01T00:00:00Z",
+			URL:  "https://example.com/a?x=1&y=2",
+			Text: "this post",
+			Chat: "general",
+			Tags: []string{"general"},
+			Context: &types.MessageContext{
+				From:         "Ada",
+				FromID:       "U01",
+				DateUnixtime: "1714521600",
+				Note:         "Read this post and <@U02>",
+			},
+			Hint: &types.PreviewHint{Title: "A", Description: "About A"},
+		},
+		{
+			ID:      2,
+			Date:    "2024-05-02T00:00:00Z",
+			URL:     "https://example.org/b",
+			Text:    "B",
+			Chat:    "general",
+			Tags:    []string{"general"},
+			Context: &types.MessageContext{DateUnixtime: "1714608000", Note: "shared"},
+			Hint:    &types.PreviewHint{Title: "B"},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestSlackImporterChannel(t *testing.T) {
+	input := `[{"type": "message", "text": "<https://example.com/>", "ts": "1714521600.000100"}]`
+	tests := map[string]string{
+		filepath.Join("general", "2024-05-01.json"):          "general",
+		filepath.Join("export", "random", "2024-05-01.json"): "random",
+		"2024-05-01.json":                              "",
+		filepath.Join(".", "2024-05-01.json"):          "",
+		filepath.Join("..", "2024-05-01.json"):         "",
+		string(filepath.Separator) + "2024-05-01.json": "",
+	}
+	for path, channel := range tests {
+		result, err := (imports.SlackImporter{}).ExtractFile(path, strings.NewReader(input), imports.Options{})
+		if err != nil {
+			t.Fatalf("ExtractFile failed: %v", err)
+		}
+		if len(result.Records) != 1 {
+			t.Fatalf("Expected 1 record for %s, got %+v", path, result.Records)
+		}
+		if result.Records[0].Chat != channel {
+			t.Errorf("Expected channel %q for %s, got %q", channel, path, result.Records[0].Chat)
+		}
+		if channel == "" && result.Records[0].Tags != nil {
+			t.Errorf("Expected no channel tag for %s, got %q", path, result.Records[0].Tags)
+		}
+	}
+}
+
+func TestProcessImportsSlackExportDirectory(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"users.json":    `[{"id": "U01", "name": "ada"}]`,
+		"channels.json": `[{"id": "C01", "name": "general"}, {"id": "C02", "name": "random"}]`,
+		filepath.Join("general", "2024-05-01.json"): `[
+			{"type": "message", "text": "<https://example.com/a>", "ts": "1714521600.000100"}
+		]`,
+		filepath.Join("random", "2024-05-02.json"): `[
+			{"type": "message", "text": "<https://example.org/b>", "ts": "1714608000.000100"}
+		]`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write input file: %v", err)
+		}
+	}
+	expected := []types.URLRecord{
+		{ID: 1, Date: "2024-05-01T00:00:00Z", URL: "https://example.com/a", Chat: "general", Tags: []string{"general"}},
+		{ID: 2, Date: "2024-05-02T00:00:00Z", URL: "https://example.org/b", Chat: "random", Tags: []string{"random"}},
+	}
+
+	for name, pattern := range map[string]string{"Directory": root, "Glob": filepath.Join(root, "*")} {
+		t.Run(name, func(t *testing.T) {
+			inputPaths, err := imports.ExpandInputPaths([]string{pattern})
+			if err != nil {
+				t.Fatalf("ExpandInputPaths failed: %v", err)
+			}
+			tempOutputFile := filepath.Join(t.TempDir(), "urls.json")
+			if err = imports.ProcessImports(inputPaths, tempOutputFile, imports.Options{}); err != nil {
+				t.Fatalf("ProcessImports failed: %v", err)
+			}
+			var result []types.URLRecord
+			if err = utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+				t.Fatalf("Failed to read output JSON file: %v", err)
+			}
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("Expected %+v, got %+v", expected, result)
+			}
+		})
+	}
+}
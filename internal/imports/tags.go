@@ -0,0 +1,61 @@
+package imports
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"link-builder/internal/utils"
+)
+
+const entityTypeHashtag = "hashtag"
+
+// LoadTagAliases reads a JSON object mapping tags to the tag they should be
+// replaced with, e.g. {"golang": "go"}. Mapping a tag to "" drops it.
+func LoadTagAliases(filePath string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	if err := utils.ReadJSONFile(filePath, &aliases); err != nil {
+		return nil, fmt.Errorf("loading tag aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// normalizeTags strips leading '#' characters, applies case folding and
+// aliases and removes empty and duplicate tags. It always returns a new slice.
+func normalizeTags(tags []string, options Options) []string {
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(strings.TrimLeft(tag, "#"))
+		if options.TagCaseFold {
+			tag = strings.ToLower(tag)
+		}
+		if alias, exists := lookupAlias(options.TagAliases, tag, options.TagCaseFold); exists {
+			tag = alias
+		}
+		if tag != "" {
+			normalized = appendTag(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+func lookupAlias(aliases map[string]string, tag string, caseFold bool) (string, bool) {
+	if alias, exists := aliases[tag]; exists {
+		return alias, true
+	}
+	if caseFold {
+		for key, alias := range aliases {
+			if strings.ToLower(key) == tag {
+				return strings.ToLower(alias), true
+			}
+		}
+	}
+	return "", false
+}
+
+func appendTag(tags []string, tag string) []string {
+	if slices.Contains(tags, tag) {
+		return tags
+	}
+	return append(tags, tag)
+}
@@ -0,0 +1,87 @@
+package imports_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportHashtags(t *testing.T) {
+	mockInput := `{"messages": [
+		{"date": "2025-05-01", "text_entities": [
+			{"type": "hashtag", "text": "#Go"},
+			{"type": "plain", "text": " "},
+			{"type": "hashtag", "text": "#golang"},
+			{"type": "link", "text": "http://example.com"},
+			{"type": "text_link", "text": "docs", "href": "http://example.org"},
+			{"type": "hashtag", "text": "#Security"}
+		]},
+		{"date": "2025-05-02", "text_entities": [{"type": "link", "text": "http://example.net"}]}
+	]}`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_hashtag_input.json")
+	defer os.Remove(tempInputFile)
+
+	tests := []struct {
+		name     string
+		options  imports.Options
+		expected []string
+	}{
+		{
+			name:     "AsIs",
+			options:  imports.Options{},
+			expected: []string{"Go", "golang", "Security"},
+		},
+		{
+			name:     "CaseFoldAndAliases",
+			options:  imports.Options{TagCaseFold: true, TagAliases: map[string]string{"Golang": "Go", "security": ""}},
+			expected: []string{"go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempOutputFile := utils.CreateTempFile(t, "", "mock_hashtag_output.json")
+			defer os.Remove(tempOutputFile)
+
+			if err := imports.ProcessImport(tempInputFile, tempOutputFile, tt.options); err != nil {
+				t.Fatalf("ProcessImport failed: %v", err)
+			}
+
+			var result []types.URLRecord
+			if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+				t.Fatalf("Failed to read output JSON file: %v", err)
+			}
+			if len(result) != 3 {
+				t.Fatalf("Expected 3 URLs, got %+v", result)
+			}
+			for _, record := range result[:2] {
+				if !reflect.DeepEqual(record.Tags, tt.expected) {
+					t.Errorf("Expected tags %v for %s, got %v", tt.expected, record.URL, record.Tags)
+				}
+			}
+			if result[2].Tags != nil {
+				t.Errorf("Expected no tags for %s, got %v", result[2].URL, result[2].Tags)
+			}
+		})
+	}
+}
+
+func TestLoadTagAliases(t *testing.T) {
+	tempFile := utils.CreateTempFile(t, `{"golang": "go"}`, "mock_tag_aliases.json")
+	defer os.Remove(tempFile)
+
+	aliases, err := imports.LoadTagAliases(tempFile)
+	if err != nil {
+		t.Fatalf("LoadTagAliases failed: %v", err)
+	}
+	if aliases["golang"] != "go" {
+		t.Errorf("Unexpected aliases: %v", aliases)
+	}
+
+	if _, err = imports.LoadTagAliases("non_existent_aliases.json"); err == nil {
+		t.Errorf("Expected error for missing alias file, got nil")
+	}
+}
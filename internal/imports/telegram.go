@@ -0,0 +1,332 @@
+package imports
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"link-builder/internal/types"
+)
+
+const (
+	entityTypeLink     = "link"
+	entityTypeTextLink = "text_link"
+)
+
+type telegramEntity struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+	Href string `json:"href"`
+}
+
+type telegramMessage struct {
+	ID            int64            `json:"id"`
+	Date          string           `json:"date"`
+	DateUnixtime  string           `json:"date_unixtime"`
+	Edited        string           `json:"edited"`
+	From          string           `json:"from"`
+	FromID        string           `json:"from_id"`
+	ForwardedFrom string           `json:"forwarded_from"`
+	TextEntities  []telegramEntity `json:"text_entities"`
+}
+
+// hashtags returns the hashtags of the message, which become the tags of
+// every URL shared in it.
+func (m telegramMessage) hashtags() []string {
+	var hashtags []string
+	for _, entity := range m.TextEntities {
+		if entity.Type == entityTypeHashtag {
+			hashtags = appendTag(hashtags, entity.Text)
+		}
+	}
+	return hashtags
+}
+
+// context returns the message details that are attached to its URL records.
+func (m telegramMessage) context() *types.MessageContext {
+	var note strings.Builder
+	for _, entity := range m.TextEntities {
+		if entity.Type != entityTypeLink {
+			note.WriteString(entity.Text)
+		}
+	}
+	return &types.MessageContext{
+		MessageID:     m.ID,
+		From:          m.From,
+		FromID:        m.FromID,
+		DateUnixtime:  m.DateUnixtime,
+		Edited:        m.Edited,
+		ForwardedFrom: m.ForwardedFrom,
+		Note:          strings.Join(strings.Fields(note.String()), " "),
+	}
+}
+
+// telegramChat holds the metadata of a chat. Its messages are streamed and
+// never held in memory as a whole.
+type telegramChat struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TelegramImporter reads Telegram Desktop JSON exports, both the single chat
+// shape with a top-level messages array and "Export all data" with chats.list.
+// The export is decoded token by token, so memory use depends on the number
+// of URLs found rather than on the size of the export.
+type TelegramImporter struct{}
+
+func (TelegramImporter) Name() string {
+	return "telegram"
+}
+
+func (TelegramImporter) Detect(prefix []byte) bool {
+	trimmed := bytes.TrimSpace(prefix)
+	if !bytes.HasPrefix(trimmed, []byte("{")) {
+		return false
+	}
+	return bytes.Contains(trimmed, []byte(`"messages"`)) ||
+		bytes.Contains(trimmed, []byte(`"chats"`)) ||
+		bytes.Contains(trimmed, []byte(`"personal_information"`))
+}
+
+func (TelegramImporter) Extract(r io.Reader, options Options) (Result, error) {
+	stream := telegramStream{
+		jsonStream: jsonStream{decoder: json.NewDecoder(r)},
+		options:    options,
+		result:     Result{Records: []types.URLRecord{}, Breakdown: make(map[string]int)},
+	}
+	if err := stream.readExport(); err != nil {
+		return Result{}, fmt.Errorf("parsing Telegram export JSON: %w", err)
+	}
+	if stream.skippedChats > 0 {
+		log.Printf("Skipped chats not matching the chat filter: %d", stream.skippedChats)
+	}
+	return stream.result, nil
+}
+
+// ChatFilter selects chats of a Telegram export by name, ID or type.
+// Empty fields match every chat.
+type ChatFilter struct {
+	Names []string
+	IDs   []int64
+	Types []string
+}
+
+func (f ChatFilter) matches(chat telegramChat) bool {
+	if len(f.Names) > 0 && !slices.ContainsFunc(f.Names, func(name string) bool {
+		return strings.EqualFold(name, chat.Name)
+	}) {
+		return false
+	}
+	if len(f.IDs) > 0 && !slices.Contains(f.IDs, chat.ID) {
+		return false
+	}
+	if len(f.Types) > 0 && !slices.Contains(f.Types, chat.Type) {
+		return false
+	}
+	return true
+}
+
+// telegramStream decodes an export one message at a time and collects the
+// URL records of the chats selected by the chat filter.
+type telegramStream struct {
+	jsonStream
+	options      Options
+	result       Result
+	skippedChats int
+}
+
+// pendingURL is a URL record of a chat whose metadata may not be complete
+// yet, because JSON does not guarantee that name and ID precede messages.
+type pendingURL struct {
+	record     types.URLRecord
+	entityType string
+	messageID  int64
+	linkIndex  int
+}
+
+// chatState accumulates a chat object while it is being decoded.
+type chatState struct {
+	chat        telegramChat
+	urls        []pendingURL
+	hasMessages bool
+}
+
+// readExport reads the top-level object. A single chat export is itself a
+// chat object, a full-account export has its chats in chats.list.
+func (s *telegramStream) readExport() error {
+	if err := s.expectDelim('{'); err != nil {
+		return err
+	}
+	var state chatState
+	hasChatList := false
+	for s.decoder.More() {
+		key, err := s.readKey()
+		if err != nil {
+			return err
+		}
+		if key == "chats" {
+			hasChatList = true
+			err = s.readChatList()
+		} else {
+			err = s.readChatField(key, &state)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := s.expectDelim('}'); err != nil {
+		return err
+	}
+	if state.hasMessages || !hasChatList {
+		s.finishChat(state)
+	}
+	return nil
+}
+
+// readChatList reads the chats object of a full-account export.
+func (s *telegramStream) readChatList() error {
+	if err := s.expectDelim('{'); err != nil {
+		return err
+	}
+	for s.decoder.More() {
+		key, err := s.readKey()
+		if err != nil {
+			return err
+		}
+		if key != "list" {
+			if err = s.skipValue(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = s.expectDelim('['); err != nil {
+			return err
+		}
+		for s.decoder.More() {
+			if err = s.readChat(); err != nil {
+				return err
+			}
+		}
+		if err = s.expectDelim(']'); err != nil {
+			return err
+		}
+	}
+	return s.expectDelim('}')
+}
+
+func (s *telegramStream) readChat() error {
+	if err := s.expectDelim('{'); err != nil {
+		return err
+	}
+	var state chatState
+	for s.decoder.More() {
+		key, err := s.readKey()
+		if err != nil {
+			return err
+		}
+		if err = s.readChatField(key, &state); err != nil {
+			return err
+		}
+	}
+	if err := s.expectDelim('}'); err != nil {
+		return err
+	}
+	s.finishChat(state)
+	return nil
+}
+
+func (s *telegramStream) readChatField(key string, state *chatState) error {
+	switch key {
+	case "id":
+		return s.decoder.Decode(&state.chat.ID)
+	case "name":
+		return s.decoder.Decode(&state.chat.Name)
+	case "type":
+		return s.decoder.Decode(&state.chat.Type)
+	case "messages":
+		state.hasMessages = true
+		return s.readMessages(state)
+	default:
+		return s.skipValue()
+	}
+}
+
+func (s *telegramStream) readMessages(state *chatState) error {
+	if err := s.expectDelim('['); err != nil {
+		return err
+	}
+	for s.decoder.More() {
+		var message telegramMessage
+		if err := s.decoder.Decode(&message); err != nil {
+			return err
+		}
+		state.urls = append(state.urls, s.messageURLs(message)...)
+	}
+	return s.expectDelim(']')
+}
+
+// messageURLs returns a record for every link and text_link entity of message.
+func (s *telegramStream) messageURLs(message telegramMessage) []pendingURL {
+	var context *types.MessageContext
+	if s.options.attachContext() {
+		context = message.context()
+	}
+	hashtags := message.hashtags()
+	date := message.Date
+	if s.options.DateUnixtime && message.DateUnixtime != "" {
+		date = unixDate(message.DateUnixtime)
+	}
+
+	var urls []pendingURL
+	for _, entity := range message.TextEntities {
+		if os.Getenv("DEBUG") == "true" {
+			log.Printf("Processing entity: %+v", entity)
+		}
+		record := types.URLRecord{
+			Date:    date,
+			Tags:    slices.Clone(hashtags),
+			Context: context,
+		}
+		switch entity.Type {
+		case entityTypeLink:
+			record.URL = entity.Text
+		case entityTypeTextLink:
+			record.URL = entity.Href
+			record.Text = entity.Text
+		default:
+			continue
+		}
+		urls = append(urls, pendingURL{
+			record:     record,
+			entityType: entity.Type,
+			messageID:  message.ID,
+			linkIndex:  len(urls) + 1,
+		})
+	}
+	return urls
+}
+
+// finishChat adds the records of a completely read chat to the result if the
+// chat is selected by the chat filter.
+func (s *telegramStream) finishChat(state chatState) {
+	if !s.options.ChatFilter.matches(state.chat) {
+		s.skippedChats++
+		return
+	}
+	for _, pending := range state.urls {
+		record := pending.record
+		record.Chat = state.chat.Name
+		record.ChatID = state.chat.ID
+		if s.options.IDMode == IDModeMessage {
+			record.UID = messageUID(state.chat.ID, pending.messageID, pending.linkIndex)
+		}
+		s.result.Records = append(s.result.Records, record)
+		s.result.Breakdown[pending.entityType]++
+	}
+}
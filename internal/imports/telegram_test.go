@@ -0,0 +1,199 @@
+package imports_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"link-builder/internal/imports"
+)
+
+func TestTelegramImporterExtractStreaming(t *testing.T) {
+	// Chat metadata after the messages, and sections that are skipped.
+	mockInput := `{
+		"about": "Here is the data you requested.",
+		"contacts": {"list": [{"first_name": "Alice", "phone_number": "+49"}]},
+		"chats": {"about": "", "list": [
+			{"messages": [
+				{"id": 1, "date": "2025-05-01", "text": ["x", {"type": "link", "text": "http://example.com"}],
+				 "text_entities": [{"type": "link", "text": "http://example.com"}]}
+			], "name": "Links", "type": "public_channel", "id": 1001},
+			{"name": "Family", "type": "private_group", "id": 1002, "messages": [
+				{"id": 2, "date": "2025-05-02", "text_entities": [{"type": "link", "text": "http://example.org"}]}
+			]}
+		]},
+		"left_chats": {"about": "", "list": [
+			{"name": "Old", "id": 1003, "messages": [
+				{"id": 3, "date": "2025-05-03", "text_entities": [{"type": "link", "text": "http://example.net"}]}
+			]}
+		]}
+	}`
+
+	options := imports.Options{
+		ChatFilter: imports.ChatFilter{Types: []string{"public_channel"}},
+		IDMode:     imports.IDModeMessage,
+	}
+	result, err := imports.TelegramImporter{}.Extract(strings.NewReader(mockInput), options)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(result.Records) != 1 {
+		t.Fatalf("Expected 1 record, got %+v", result.Records)
+	}
+	record := result.Records[0]
+	if record.URL != "http://example.com" || record.Chat != "Links" || record.ChatID != 1001 ||
+		record.UID != "1001-1-1" {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+	if result.Breakdown["link"] != 1 {
+		t.Errorf("Unexpected breakdown: %v", result.Breakdown)
+	}
+}
+
+func TestTelegramImporterExtractErrors(t *testing.T) {
+	inputs := map[string]string{
+		"NotAnObject":     `[{"messages": []}]`,
+		"Truncated":       `{"messages": [{"date": "2025-05-01"`,
+		"MessagesObject":  `{"messages": {}}`,
+		"InvalidChatList": `{"chats": {"list": {}}}`,
+	}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := (imports.TelegramImporter{}).Extract(strings.NewReader(input), imports.Options{}); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+// writeTelegramExport writes a single chat export with the given number of
+// messages, every tenth of which shares a link.
+func writeTelegramExport(w io.Writer, messages int) error {
+	if _, err := io.WriteString(w, `{"name": "Links", "type": "public_channel", "id": 1001, "messages": [`); err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	for i := range messages {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		entities := []map[string]string{{"type": "plain", "text": strings.Repeat("lorem ipsum ", 20)}}
+		if i%10 == 0 {
+			entities = append(entities, map[string]string{"type": "link", "text": fmt.Sprintf("https://example.com/%d", i)})
+		}
+		if err := encoder.Encode(map[string]any{
+			"id":            i,
+			"type":          "message",
+			"date":          "2025-05-01T10:00:00",
+			"from":          "Alice",
+			"text_entities": entities,
+		}); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]}")
+	return err
+}
+
+// peakHeap samples the heap in use while fn runs and returns the maximum.
+func peakHeap(fn func()) uint64 {
+	runtime.GC()
+	var (
+		peak  uint64
+		mutex sync.Mutex
+		stats runtime.MemStats
+	)
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			runtime.ReadMemStats(&stats)
+			mutex.Lock()
+			peak = max(peak, stats.HeapInuse)
+			mutex.Unlock()
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	fn()
+	close(done)
+	<-sampled
+	mutex.Lock()
+	defer mutex.Unlock()
+	return peak
+}
+
+// BenchmarkTelegramImporterExtract compares streaming extraction with decoding
+// the whole export at once. The peak-heap-MB metric of the streaming variant
+// only grows with the URLs found, while the full decode grows with the file.
+func BenchmarkTelegramImporterExtract(b *testing.B) {
+	for _, messages := range []int{10_000, 100_000} {
+		var export bytes.Buffer
+		if err := writeTelegramExport(&export, messages); err != nil {
+			b.Fatalf("Failed to generate export: %v", err)
+		}
+		path := filepath.Join(b.TempDir(), "export.json")
+		if err := os.WriteFile(path, export.Bytes(), 0600); err != nil {
+			b.Fatalf("Failed to write export: %v", err)
+		}
+		export.Reset()
+
+		b.Run(fmt.Sprintf("stream/messages=%d", messages), func(b *testing.B) {
+			var peak uint64
+			for b.Loop() {
+				peak = max(peak, peakHeap(func() {
+					file, err := os.Open(path)
+					if err != nil {
+						b.Fatalf("Failed to open export: %v", err)
+					}
+					defer file.Close()
+					if _, err = (imports.TelegramImporter{}).Extract(file, imports.Options{}); err != nil {
+						b.Fatalf("Extract failed: %v", err)
+					}
+				}))
+			}
+			b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+		})
+
+		b.Run(fmt.Sprintf("unmarshal/messages=%d", messages), func(b *testing.B) {
+			var peak uint64
+			for b.Loop() {
+				peak = max(peak, peakHeap(func() {
+					data, err := os.ReadFile(path)
+					if err != nil {
+						b.Fatalf("Failed to read export: %v", err)
+					}
+					var input struct {
+						Messages []struct {
+							Date         string `json:"date"`
+							TextEntities []struct {
+								Type string `json:"type"`
+								Text string `json:"text"`
+							} `json:"text_entities"`
+						} `json:"messages"`
+					}
+					if err = json.Unmarshal(data, &input); err != nil {
+						b.Fatalf("Unmarshal failed: %v", err)
+					}
+				}))
+			}
+			b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package imports
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"link-builder/internal/types"
+)
+
+// textFileExtensions are the extensions of the files collected from input
+// directories.
+func textFileExtensions() []string {
+	return []string{".txt", ".md", ".markdown"}
+}
+
+func isTextFile(path string) bool {
+	extension := strings.ToLower(filepath.Ext(path))
+	for _, candidate := range textFileExtensions() {
+		if extension == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// frontMatterDateLayouts are the date formats accepted in the date field of
+// Markdown front matter, tried in order.
+func frontMatterDateLayouts() []string {
+	return []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05 -0700",
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+	}
+}
+
+// textLinkPattern matches, in order of precedence, Markdown inline links with
+// an optional title, autolinks in angle brackets and bare URLs.
+func textLinkPattern() *regexp.Regexp {
+	return regexp.MustCompile(
+		`\[([^\]]*)\]\(\s*<?((?i:https?)://[^\s<>()]+(?:\([^\s<>()]*\)[^\s<>()]*)*)>?(?:\s+(?:"[^"]*"|'[^']*'))?\s*\)` +
+			`|<((?i:https?)://[^\s<>]+)>` +
+			"|((?i:https?)://[^\\s<>\"'`]+)")
+}
+
+// TextImporter reads plain-text notes and Markdown files. Markdown links,
+// autolinks and bare URLs each yield a record with the file and line they were
+// found on. The record date is the date field of the front matter, or the
+// modification time of the file.
+type TextImporter struct{}
+
+func (TextImporter) Name() string {
+	return "text"
+}
+
+// Detect accepts any text containing an http(s) URL, so the text importer
+// must be registered last.
+func (TextImporter) Detect(prefix []byte) bool {
+	if bytes.IndexByte(prefix, 0) != -1 {
+		return false
+	}
+	lower := bytes.ToLower(prefix)
+	return bytes.Contains(lower, []byte("http://")) || bytes.Contains(lower, []byte("https://"))
+}
+
+func (TextImporter) Extract(r io.Reader, _ Options) (Result, error) {
+	allURLs, err := extractTextURLs(r, "", "")
+	if err != nil {
+		return Result{}, fmt.Errorf("reading text: %w", err)
+	}
+	return Result{Records: allURLs}, nil
+}
+
+func (TextImporter) ExtractFile(path string, r io.Reader, _ Options) (Result, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Result{}, fmt.Errorf("reading file info: %w", err)
+	}
+	allURLs, err := extractTextURLs(r, path, formatDate(info.ModTime()))
+	if err != nil {
+		return Result{}, fmt.Errorf("reading text: %w", err)
+	}
+	return Result{Records: allURLs}, nil
+}
+
+// extractTextURLs returns a record for every link in r. Front matter is only
+// read for its date; defaultDate is used when it has none.
+func extractTextURLs(r io.Reader, source, defaultDate string) ([]types.URLRecord, error) {
+	allURLs := []types.URLRecord{}
+	pattern := textLinkPattern()
+	reader := bufio.NewReader(r)
+	date := defaultDate
+	frontMatterEnd := ""
+
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if line == "" && err != nil {
+			return allURLs, nil
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, utf8BOM)
+		}
+
+		switch {
+		case lineNumber == 1 && (line == "---" || line == "+++"):
+			frontMatterEnd = line
+		case frontMatterEnd != "":
+			if line == frontMatterEnd || (frontMatterEnd == "---" && line == "...") {
+				frontMatterEnd = ""
+			} else if value, ok := frontMatterDate(line); ok {
+				if parsed := sourceDate(value, frontMatterDateLayouts()...); parsed != "" {
+					date = parsed
+				}
+			}
+		default:
+			allURLs = append(allURLs, lineRecords(pattern, line, source, lineNumber, date)...)
+		}
+	}
+}
+
+// frontMatterDate returns the value of a YAML "date:" or TOML "date =" line.
+func frontMatterDate(line string) (string, bool) {
+	key, value, found := strings.Cut(line, ":")
+	if !found || strings.TrimSpace(key) != "date" {
+		if key, value, found = strings.Cut(line, "="); !found || strings.TrimSpace(key) != "date" {
+			return "", false
+		}
+	}
+	return strings.Trim(strings.TrimSpace(value), `"'`), true
+}
+
+func lineRecords(pattern *regexp.Regexp, line, source string, lineNumber int, date string) []types.URLRecord {
+	links, _ := textLinks(pattern, line)
+	records := make([]types.URLRecord, 0, len(links))
+	for _, link := range links {
+		records = append(records, types.URLRecord{
+			Date:   date,
+			URL:    link.url,
+			Text:   link.text,
+			Source: source,
+			Line:   lineNumber,
+		})
+	}
+	return records
+}
+
+// textLink is a URL found in text along with its link text, if any.
+type textLink struct {
+	url  string
+	text string
+}
+
+// textLinks returns the links of text matched by textLinkPattern, and the text
+// with bare URLs and autolinks removed and Markdown links replaced by their
+// link text.
+func textLinks(pattern *regexp.Regexp, text string) ([]textLink, string) {
+	var (
+		links     []textLink
+		remainder strings.Builder
+		last      int
+	)
+	for _, match := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		remainder.WriteString(text[last:match[0]])
+		last = match[1]
+
+		var link textLink
+		switch {
+		case match[4] != -1:
+			link.url = text[match[4]:match[5]]
+			if label := strings.TrimSpace(text[match[2]:match[3]]); label != link.url {
+				link.text = label
+				remainder.WriteString(label)
+			}
+		case match[6] != -1:
+			link.url = text[match[6]:match[7]]
+		default:
+			bare := text[match[8]:match[9]]
+			link.url = trimBareURL(bare)
+			remainder.WriteString(bare[len(link.url):])
+		}
+		links = append(links, link)
+	}
+	remainder.WriteString(text[last:])
+	return links, remainder.String()
+}
+
+// trimBareURL removes trailing punctuation that belongs to the surrounding
+// sentence, including a closing parenthesis without an opening one in the URL.
+func trimBareURL(rawURL string) string {
+	for {
+		trimmed := strings.TrimRight(rawURL, ".,;:!?*")
+		if strings.HasSuffix(trimmed, ")") && strings.Count(trimmed, "(") < strings.Count(trimmed, ")") {
+			trimmed = strings.TrimSuffix(trimmed, ")")
+		}
+		if trimmed == rawURL {
+			return rawURL
+		}
+		rawURL = trimmed
+	}
+}
@@ -0,0 +1,130 @@
+package imports_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/types"
+	"link-builder/internal/utils"
+)
+
+func TestProcessImportsTextDirectory(t *testing.T) {
+	markdownInput := `---
+title: Reading list
+date: 2024-05-01
+---
+# Links
+
+- [Go blog](https://go.dev/blog "The Go Blog") and <https://pkg.go.dev/>
+- See https://en.wikipedia.org/wiki/Go_(programming_language).
+`
+	textInput := "notes\r\nhttps://example.com/a, https://example.com/b!\r\n"
+
+	dir := t.TempDir()
+	markdownFile := filepath.Join(dir, "notes", "reading.md")
+	textFile := filepath.Join(dir, "notes", "daily", "today.txt")
+	if err := os.MkdirAll(filepath.Dir(textFile), 0700); err != nil {
+		t.Fatalf("Failed to create input directory: %v", err)
+	}
+	for path, content := range map[string]string{
+		markdownFile:                         markdownInput,
+		textFile:                             textInput,
+		filepath.Join(dir, "notes", "a.pdf"): "https://example.com/ignored",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write input file: %v", err)
+		}
+	}
+	modTime := time.Date(2024, 6, 2, 8, 30, 0, 0, time.UTC)
+	if err := os.Chtimes(textFile, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	inputPaths, err := imports.ExpandInputPaths([]string{filepath.Join(dir, "notes")})
+	if err != nil {
+		t.Fatalf("ExpandInputPaths failed: %v", err)
+	}
+	if expected := []string{textFile, markdownFile}; !reflect.DeepEqual(inputPaths, expected) {
+		t.Fatalf("Expected input paths %v, got %v", expected, inputPaths)
+	}
+
+	tempOutputFile := filepath.Join(dir, "urls.json")
+	if err = imports.ProcessImports(inputPaths, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImports failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err = utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{ID: 1, Date: "2024-05-01T00:00:00Z", URL: "https://go.dev/blog", Text: "Go blog", Source: markdownFile, Line: 7},
+		{ID: 2, Date: "2024-05-01T00:00:00Z", URL: "https://pkg.go.dev/", Source: markdownFile, Line: 7},
+		{ID: 3, Date: "2024-05-01T00:00:00Z", URL: "https://en.wikipedia.org/wiki/Go_(programming_language)", Source: markdownFile, Line: 8},
+		{ID: 4, Date: "2024-06-02T08:30:00Z", URL: "https://example.com/a", Source: textFile, Line: 2},
+		{ID: 5, Date: "2024-06-02T08:30:00Z", URL: "https://example.com/b", Source: textFile, Line: 2},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestProcessImportsTextDirectoryWithoutLinks(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.md":  "# Links\n\nhttps://example.com/a\n",
+		"b.md":  "# Ideas\n\nNo links in this note yet.\n",
+		"c.txt": "",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write input file: %v", err)
+		}
+	}
+
+	inputPaths, err := imports.ExpandInputPaths([]string{dir})
+	if err != nil {
+		t.Fatalf("ExpandInputPaths failed: %v", err)
+	}
+	tempOutputFile := filepath.Join(dir, "urls.json")
+	if err = imports.ProcessImports(inputPaths, tempOutputFile, imports.Options{}); err != nil {
+		t.Fatalf("ProcessImports failed: %v", err)
+	}
+
+	var result []types.URLRecord
+	if err = utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+	if len(result) != 1 || result[0].URL != "https://example.com/a" {
+		t.Errorf("Expected only the link of a.md, got %+v", result)
+	}
+}
+
+func TestTextImporterExtract(t *testing.T) {
+	input := "+++\ndate = \"2024-05-01T10:00:00Z\"\nlink = \"https://example.com/front-matter\"\n+++\n" +
+		"[https://example.com](https://example.com) https://example.com/x)\n"
+
+	result, err := imports.TextImporter{}.Extract(strings.NewReader(input), imports.Options{})
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	expected := []types.URLRecord{
+		{Date: "2024-05-01T10:00:00Z", URL: "https://example.com", Line: 5},
+		{Date: "2024-05-01T10:00:00Z", URL: "https://example.com/x", Line: 5},
+	}
+	if !reflect.DeepEqual(result.Records, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result.Records)
+	}
+}
+
+func TestExpandInputPathsEmptyDirectory(t *testing.T) {
+	if _, err := imports.ExpandInputPaths([]string{t.TempDir()}); err == nil {
+		t.Errorf("Expected error for directory without text files, got nil")
+	}
+}
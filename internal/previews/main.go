@@ -99,11 +99,7 @@ func GenerateLinkPreviews(inputFilePath, outputFilePath string, previewer LinkPr
 }
 
 // Helper functions for GenerateLinkPreviews.
-func parseInputFile(inputFilePath string) ([]struct {
-	ID   int    `json:"id"`
-	Date string `json:"date"`
-	URL  string `json:"url"`
-}, error) {
+func parseInputFile(inputFilePath string) ([]types.URLRecord, error) {
 	data, err := os.ReadFile(inputFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("reading input file: %w", err)
@@ -113,11 +109,7 @@ func parseInputFile(inputFilePath string) ([]struct {
 		return nil, fmt.Errorf("input JSON is invalid: %s", inputFilePath)
 	}
 
-	var urlObjects []struct {
-		ID   int    `json:"id"`
-		Date string `json:"date"`
-		URL  string `json:"url"`
-	}
+	var urlObjects []types.URLRecord
 	if err = json.Unmarshal(data, &urlObjects); err != nil {
 		return nil, fmt.Errorf("parsing input JSON: %w", err)
 	}
@@ -134,11 +126,7 @@ func parseInputFile(inputFilePath string) ([]struct {
 	return urlObjects, nil
 }
 
-func ParseInputFile(inputFilePath string) ([]struct {
-	ID   int    `json:"id"`
-	Date string `json:"date"`
-	URL  string `json:"url"`
-}, error) {
+func ParseInputFile(inputFilePath string) ([]types.URLRecord, error) {
 	return parseInputFile(inputFilePath)
 }
 
@@ -171,7 +159,9 @@ func loadCache(outputFilePath string) (map[string]interface{}, error) {
 		var cacheArray []types.LinkPreviewOutput
 		if err = json.Unmarshal(cacheData, &cacheArray); err == nil {
 			for _, item := range cacheArray {
-				cache[item.URL] = item.Preview
+				if !item.HintOnly {
+					cache[item.URL] = item.Preview
+				}
 			}
 		} else if string(cacheData) == "[]" {
 			cache = make(map[string]interface{})
@@ -187,11 +177,12 @@ func LoadCache(outputFilePath string) (map[string]interface{}, error) {
 	return loadCache(outputFilePath)
 }
 
-func generatePreviews(urlObjects []struct {
-	ID   int    `json:"id"`
-	Date string `json:"date"`
-	URL  string `json:"url"`
-}, cache map[string]interface{}, previewer LinkPreviewer, outputFilePath string) ([]types.LinkPreviewOutput, error) {
+func generatePreviews(
+	urlObjects []types.URLRecord,
+	cache map[string]interface{},
+	previewer LinkPreviewer,
+	outputFilePath string,
+) ([]types.LinkPreviewOutput, error) {
 	totalURLs := len(urlObjects)
 	cachedCount := 0
 	for _, urlObj := range urlObjects {
@@ -209,8 +200,14 @@ func generatePreviews(urlObjects []struct {
 		log.Printf("Processing URL %d/%d: %s", currentCount, totalURLs, urlObj.URL)
 
 		preview, exists := cache[urlObj.URL]
+		hintOnly := false
 		if !exists {
 			parsedPreview, err := previewer.Parse(urlObj.URL)
+			if err != nil && urlObj.Hint != nil {
+				log.Printf("Failed to generate preview for %s, using preview hint: %v", urlObj.URL, err)
+				parsedPreview, err = &Preview{}, nil
+				hintOnly = true
+			}
 			if err != nil {
 				log.Printf("Failed to generate preview for %s: %v", urlObj.URL, err)
 				continue
@@ -220,6 +217,7 @@ func generatePreviews(urlObjects []struct {
 				log.Printf("Skipping nil preview for %s", urlObj.URL)
 				continue
 			}
+			applyHint(parsedPreview, urlObj.Hint)
 
 			if parsedPreview.Title == "" &&
 				parsedPreview.Description == "" &&
@@ -234,14 +232,25 @@ func generatePreviews(urlObjects []struct {
 				"og_meta":      parsedPreview.OGMeta,
 				"twitter_meta": parsedPreview.TwitterMeta,
 			}
-			cache[urlObj.URL] = preview
+			// Previews of the hint alone are fetched again on the next run.
+			if !hintOnly {
+				cache[urlObj.URL] = preview
+			}
 		}
 
 		output = append(output, types.LinkPreviewOutput{
 			ID:      urlObj.ID,
+			UID:     urlObj.UID,
 			Date:    urlObj.Date,
 			URL:     urlObj.URL,
+			Tags:    urlObj.Tags,
+			Chat:    urlObj.Chat,
+			ChatID:  urlObj.ChatID,
 			Preview: preview,
+
+			DisplayURL: urlObj.DisplayURL,
+			SourceURL:  urlObj.SourceURL,
+			HintOnly:   hintOnly,
 		})
 
 		// Write the current state of the output to the file after processing each URL
@@ -258,6 +267,20 @@ func generatePreviews(urlObjects []struct {
 	return output, nil
 }
 
+// applyHint fills an empty title or description of preview from the preview
+// hint provided by the import source.
+func applyHint(preview *Preview, hint *types.PreviewHint) {
+	if hint == nil {
+		return
+	}
+	if preview.Title == "" {
+		preview.Title = hint.Title
+	}
+	if preview.Description == "" {
+		preview.Description = hint.Description
+	}
+}
+
 func saveOutput(outputFilePath string, output []types.LinkPreviewOutput) error {
 	outputData, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
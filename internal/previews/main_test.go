@@ -202,3 +202,74 @@ func TestSaveOutput(t *testing.T) {
 		t.Errorf("Expected error for invalid file path, got nil")
 	}
 }
+
+type TitleLinkPreviewer struct{}
+
+func (TitleLinkPreviewer) Parse(url string) (*previews.Preview, error) {
+	return &previews.Preview{Title: "Title of " + url}, nil
+}
+
+func TestGenerateLinkPreviewsCarriesRecordFields(t *testing.T) {
+	mockInput := `[{"id": 1, "uid": "abc123", "date": "2025-05-01", "url": "` + exampleComURL + `", "tags": ["go"],
+		"chat": "Links", "chat_id": 42}]`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_tags_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_tags_output.json")
+	defer os.Remove(tempOutputFile)
+
+	if err := previews.GenerateLinkPreviews(tempInputFile, tempOutputFile, TitleLinkPreviewer{}); err != nil {
+		t.Fatalf("GenerateLinkPreviews failed: %v", err)
+	}
+
+	var result []types.LinkPreviewOutput
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+	if len(result) != 1 || result[0].UID != "abc123" || len(result[0].Tags) != 1 || result[0].Tags[0] != "go" {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if result[0].Chat != "Links" || result[0].ChatID != 42 {
+		t.Errorf("Expected chat Links (42), got %q (%d)", result[0].Chat, result[0].ChatID)
+	}
+}
+
+func TestGenerateLinkPreviewsUsesHint(t *testing.T) {
+	mockInput := `[{"id": 1, "date": "2025-05-01", "url": "` + exampleComURL + `",
+		"preview_hint": {"title": "Feed title", "description": "Feed summary"}}]`
+	tempInputFile := utils.CreateTempFile(t, mockInput, "mock_hint_input.json")
+	defer os.Remove(tempInputFile)
+
+	tempOutputFile := utils.CreateTempFile(t, "", "mock_hint_output.json")
+	defer os.Remove(tempOutputFile)
+
+	// MockLinkPreviewer always fails, so the hint becomes the preview.
+	if err := previews.GenerateLinkPreviews(tempInputFile, tempOutputFile, MockLinkPreviewer{}); err != nil {
+		t.Fatalf("GenerateLinkPreviews failed: %v", err)
+	}
+
+	var result []struct {
+		URL      string           `json:"url"`
+		Preview  previews.Preview `json:"preview"`
+		HintOnly bool             `json:"hint_only"`
+	}
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+	if len(result) != 1 || result[0].Preview.Title != "Feed title" || result[0].Preview.Description != "Feed summary" ||
+		!result[0].HintOnly {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// Previews of the hint alone are not cached, so the next run fetches the URL.
+	if err := previews.GenerateLinkPreviews(tempInputFile, tempOutputFile, TitleLinkPreviewer{}); err != nil {
+		t.Fatalf("GenerateLinkPreviews failed: %v", err)
+	}
+	result = nil
+	if err := utils.ReadJSONFile(tempOutputFile, &result); err != nil {
+		t.Fatalf("Failed to read output JSON file: %v", err)
+	}
+	if len(result) != 1 || result[0].Preview.Title != "Title of "+exampleComURL || result[0].HintOnly {
+		t.Errorf("Expected the preview to be fetched again, got %+v", result)
+	}
+}
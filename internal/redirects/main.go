@@ -0,0 +1,284 @@
+package redirects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"link-builder/internal/utils"
+)
+
+// Defaults of Options.
+const (
+	DefaultMaxHops = 10
+	DefaultTimeout = 10 * time.Second
+)
+
+// Resolution modes, see Modes.
+const (
+	ModeShorteners = "shorteners"
+	ModeAll        = "all"
+)
+
+var (
+	ErrTooManyHops     = errors.New("too many redirects")
+	ErrInvalidLocation = errors.New("invalid redirect location")
+)
+
+// Modes returns the valid values of the resolution mode: ModeShorteners only
+// resolves URLs of DefaultShortenerHosts, ModeAll resolves every URL.
+func Modes() []string {
+	return []string{ModeShorteners, ModeAll}
+}
+
+// DefaultShortenerHosts returns the hosts of well-known URL shorteners.
+func DefaultShortenerHosts() []string {
+	return []string{
+		"t.co",
+		"bit.ly",
+		"lnkd.in",
+		"youtu.be",
+		"goo.gl",
+		"tinyurl.com",
+		"ow.ly",
+		"buff.ly",
+		"dlvr.it",
+		"is.gd",
+		"t.ly",
+		"tiny.cc",
+		"cutt.ly",
+		"rebrand.ly",
+		"trib.al",
+		"fb.me",
+		"amzn.to",
+		"redd.it",
+		"shorturl.at",
+	}
+}
+
+// Options configures a Resolver.
+type Options struct {
+	// Hosts limits resolution to URLs on these hosts and their subdomains.
+	// Empty resolves all URLs.
+	Hosts []string
+	// MaxHops is the number of redirects followed per URL. Zero means
+	// DefaultMaxHops.
+	MaxHops int
+	// Timeout bounds the resolution of a single URL, all hops included. Zero
+	// means DefaultTimeout.
+	Timeout time.Duration
+	// CachePath is the JSON file resolved URLs are cached in across runs.
+	// Empty disables the persistent cache.
+	CachePath string
+	// Client sends the requests. Nil means a client with default settings.
+	// Its redirect policy is ignored, redirects are followed by the Resolver.
+	Client *http.Client
+}
+
+// Resolution is the outcome of resolving a URL. URL is the final destination,
+// or the URL itself if it does not redirect or could not be resolved.
+type Resolution struct {
+	URL    string
+	Hops   int
+	Cached bool
+	Err    error
+}
+
+// Resolver follows the HTTP redirects of URLs to their final destination.
+type Resolver struct {
+	options Options
+	client  *http.Client
+	mutex   sync.Mutex
+	cache   map[string]string
+	dirty   bool
+}
+
+// NewResolver returns a Resolver, loading the cache of options.CachePath if it
+// exists.
+func NewResolver(options Options) (*Resolver, error) {
+	if options.MaxHops <= 0 {
+		options.MaxHops = DefaultMaxHops
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultTimeout
+	}
+	client := http.Client{}
+	if options.Client != nil {
+		client = *options.Client
+	}
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resolver := &Resolver{options: options, client: &client, cache: make(map[string]string)}
+	if options.CachePath == "" {
+		return resolver, nil
+	}
+	if _, err := os.Stat(options.CachePath); errors.Is(err, os.ErrNotExist) {
+		return resolver, nil
+	}
+	if err := utils.ReadJSONFile(options.CachePath, &resolver.cache); err != nil {
+		return nil, fmt.Errorf("loading redirect cache: %w", err)
+	}
+	return resolver, nil
+}
+
+// Applies reports whether rawURL is resolved, which depends on its host.
+func (r *Resolver) Applies(rawURL string) bool {
+	if len(r.options.Hosts) == 0 {
+		return true
+	}
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsedURL.Hostname())
+	for _, candidate := range r.options.Hosts {
+		candidate = strings.ToLower(candidate)
+		if host == candidate || strings.HasSuffix(host, "."+candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the final destination of rawURL. Cached destinations are
+// returned without requests.
+func (r *Resolver) Resolve(rawURL string) Resolution {
+	r.mutex.Lock()
+	final, cached := r.cache[rawURL]
+	r.mutex.Unlock()
+	if cached {
+		return Resolution{URL: final, Cached: true}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.options.Timeout)
+	defer cancel()
+	current := rawURL
+	for hops := 0; ; hops++ {
+		next, err := r.follow(ctx, current)
+		if err != nil {
+			return Resolution{URL: rawURL, Hops: hops, Err: fmt.Errorf("resolving %s: %w", rawURL, err)}
+		}
+		if next == "" {
+			r.mutex.Lock()
+			r.cache[rawURL] = current
+			r.dirty = true
+			r.mutex.Unlock()
+			return Resolution{URL: current, Hops: hops}
+		}
+		if hops == r.options.MaxHops {
+			return Resolution{URL: rawURL, Hops: hops, Err: fmt.Errorf("resolving %s: %w", rawURL, ErrTooManyHops)}
+		}
+		current = next
+	}
+}
+
+// ResolveAll resolves the distinct URLs of urls concurrently, keyed by URL.
+func (r *Resolver) ResolveAll(urls []string) map[string]Resolution {
+	urlChan := make(chan string, len(urls))
+	queued := make(map[string]bool, len(urls))
+	for _, rawURL := range urls {
+		if !queued[rawURL] {
+			queued[rawURL] = true
+			urlChan <- rawURL
+		}
+	}
+	close(urlChan)
+
+	var (
+		mutex       sync.Mutex
+		wg          sync.WaitGroup
+		resolutions = make(map[string]Resolution, len(queued))
+	)
+	workerCount := 10
+	for range make([]struct{}, workerCount) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rawURL := range urlChan {
+				resolution := r.Resolve(rawURL)
+				mutex.Lock()
+				resolutions[rawURL] = resolution
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return resolutions
+}
+
+// SaveCache writes the resolved URLs to the cache file, if the cache is
+// enabled and changed.
+func (r *Resolver) SaveCache() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.options.CachePath == "" || !r.dirty {
+		return nil
+	}
+	if err := utils.WriteJSONFile(r.options.CachePath, r.cache); err != nil {
+		return fmt.Errorf("saving redirect cache: %w", err)
+	}
+	r.dirty = false
+	return nil
+}
+
+// follow requests rawURL and returns the URL it redirects to, or an empty
+// string if it does not redirect. Servers that fail a HEAD request are asked
+// with GET. Redirects to URLs that are not valid http or https URLs, see
+// utils.CheckURL, are an error.
+func (r *Resolver) follow(ctx context.Context, rawURL string) (string, error) {
+	response, err := r.request(ctx, http.MethodHead, rawURL)
+	if err != nil || response.StatusCode >= http.StatusBadRequest {
+		if response, err = r.request(ctx, http.MethodGet, rawURL); err != nil {
+			return "", err
+		}
+	}
+	if !isRedirect(response.StatusCode) {
+		return "", nil
+	}
+	location := response.Header.Get("Location")
+	if location == "" {
+		return "", nil
+	}
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	target, err := base.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %w", ErrInvalidLocation, location, err)
+	}
+	if err = utils.CheckURL(target.String()); err != nil {
+		return "", fmt.Errorf("%w %q: %w", ErrInvalidLocation, location, err)
+	}
+	return target.String(), nil
+}
+
+// request sends a request without body and closes the response body.
+func (r *Resolver) request(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+	return response, nil
+}
+
+func isRedirect(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
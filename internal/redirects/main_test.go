@@ -0,0 +1,183 @@
+package redirects_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"link-builder/internal/redirects"
+)
+
+// newRedirectServer serves /short -> /middle -> /final and counts requests.
+func newRedirectServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/short", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Redirect(w, r, "/middle", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/middle", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Redirect(w, r, "/final?id=1", http.StatusFound)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		http.Redirect(w, r, "/final?id=2", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Redirect(w, r, "/loop", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/mailto", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Redirect(w, r, "mailto:someone@example.com", http.StatusFound)
+	})
+	mux.HandleFunc("/bad-host", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Redirect(w, r, "https://-bad-.example/", http.StatusFound)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestResolve(t *testing.T) {
+	var requests atomic.Int32
+	server := newRedirectServer(t, &requests)
+	resolver, err := redirects.NewResolver(redirects.Options{})
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+
+	resolution := resolver.Resolve(server.URL + "/short")
+	if resolution.Err != nil || resolution.URL != server.URL+"/final?id=1" || resolution.Hops != 2 {
+		t.Errorf("Expected %s/final?id=1 after 2 hops, got %+v", server.URL, resolution)
+	}
+
+	resolution = resolver.Resolve(server.URL + "/final")
+	if resolution.Err != nil || resolution.URL != server.URL+"/final" || resolution.Hops != 0 {
+		t.Errorf("Expected %s/final to be final, got %+v", server.URL, resolution)
+	}
+}
+
+func TestResolveFallsBackToGet(t *testing.T) {
+	var requests atomic.Int32
+	server := newRedirectServer(t, &requests)
+	resolver, err := redirects.NewResolver(redirects.Options{})
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+
+	resolution := resolver.Resolve(server.URL + "/get-only")
+	if resolution.Err != nil || resolution.URL != server.URL+"/final?id=2" {
+		t.Errorf("Expected %s/final?id=2, got %+v", server.URL, resolution)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := newRedirectServer(t, &requests)
+	resolver, err := redirects.NewResolver(redirects.Options{MaxHops: 3, Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		err  error
+	}{
+		{"/loop", redirects.ErrTooManyHops},
+		{"/mailto", redirects.ErrInvalidLocation},
+		{"/bad-host", redirects.ErrInvalidLocation},
+		{"/slow", nil},
+	}
+	for _, tt := range tests {
+		rawURL := server.URL + tt.path
+		resolution := resolver.Resolve(rawURL)
+		if resolution.Err == nil || (tt.err != nil && !errors.Is(resolution.Err, tt.err)) {
+			t.Errorf("Expected error %v for %s, got %+v", tt.err, tt.path, resolution)
+		}
+		if resolution.URL != rawURL {
+			t.Errorf("Expected unresolved %s to be kept, got %s", rawURL, resolution.URL)
+		}
+	}
+	if resolution := resolver.Resolve(server.URL + "/loop"); resolution.Cached {
+		t.Errorf("Expected failed resolutions not to be cached, got %+v", resolution)
+	}
+}
+
+func TestResolveAllCache(t *testing.T) {
+	var requests atomic.Int32
+	server := newRedirectServer(t, &requests)
+	cachePath := filepath.Join(t.TempDir(), "redirects.json")
+	urls := []string{server.URL + "/short", server.URL + "/short", server.URL + "/final"}
+
+	resolver, err := redirects.NewResolver(redirects.Options{CachePath: cachePath})
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+	resolutions := resolver.ResolveAll(urls)
+	if len(resolutions) != 2 {
+		t.Fatalf("Expected 2 distinct resolutions, got %+v", resolutions)
+	}
+	if err = resolver.SaveCache(); err != nil {
+		t.Fatalf("SaveCache failed: %v", err)
+	}
+	sent := requests.Load()
+
+	resolver, err = redirects.NewResolver(redirects.Options{CachePath: cachePath})
+	if err != nil {
+		t.Fatalf("NewResolver failed to load the cache: %v", err)
+	}
+	for rawURL, resolution := range resolver.ResolveAll(urls) {
+		if !resolution.Cached || resolution.URL != resolutions[rawURL].URL {
+			t.Errorf("Expected cached %s for %s, got %+v", resolutions[rawURL].URL, rawURL, resolution)
+		}
+	}
+	if requests.Load() != sent {
+		t.Errorf("Expected no requests for cached URLs, got %d", requests.Load()-sent)
+	}
+}
+
+func TestApplies(t *testing.T) {
+	resolver, err := redirects.NewResolver(redirects.Options{Hosts: redirects.DefaultShortenerHosts()})
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+	tests := map[string]bool{
+		"https://t.co/abc":         true,
+		"https://BIT.LY/abc":       true,
+		"https://www.youtu.be/abc": true,
+		"https://example.com/abc":  false,
+		"https://nott.co/abc":      false,
+	}
+	for rawURL, expected := range tests {
+		if applies := resolver.Applies(rawURL); applies != expected {
+			t.Errorf("Expected Applies(%s) to be %v, got %v", rawURL, expected, applies)
+		}
+	}
+
+	if resolver, err = redirects.NewResolver(redirects.Options{}); err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+	if !resolver.Applies("https://example.com/abc") {
+		t.Error("Expected a resolver without hosts to resolve all URLs")
+	}
+}
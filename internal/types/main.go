@@ -1,8 +1,68 @@
 package types
 
+// URLRecord is a single URL entry as written to and read from urls.json.
+type URLRecord struct {
+	ID     int      `json:"id"`
+	UID    string   `json:"uid,omitempty"`
+	Date   string   `json:"date"`
+	URL    string   `json:"url"`
+	Text   string   `json:"text,omitempty"`
+	Chat   string   `json:"chat,omitempty"`
+	ChatID int64    `json:"chat_id,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	// DisplayURL is URL with an internationalized host in Unicode form. URL
+	// holds the punycode form used for fetching.
+	DisplayURL string `json:"display_url,omitempty"`
+	// SourceURL is the URL as found in the source when URL is the final
+	// destination of its redirects, e.g. of a shortened URL.
+	SourceURL string `json:"source_url,omitempty"`
+	// DateInvalid marks records whose source date could not be parsed. Date
+	// then holds the date as found in the source.
+	DateInvalid bool `json:"date_invalid,omitempty"`
+	// Unread marks items that were saved in a read-later service but not read.
+	Unread bool `json:"unread,omitempty"`
+	// Source is where the URL was found, the input file of text notes or the
+	// post URL of social media archives. Line is its line in a text file.
+	Source string `json:"source,omitempty"`
+	Line   int    `json:"line,omitempty"`
+
+	Context *MessageContext `json:"context,omitempty"`
+	Hint    *PreviewHint    `json:"preview_hint,omitempty"`
+}
+
+// PreviewHint is preview metadata provided by the import source itself, such
+// as the title and summary of a feed item.
+type PreviewHint struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// MessageContext describes the message a URL was shared in.
+type MessageContext struct {
+	MessageID     int64  `json:"message_id,omitempty"`
+	From          string `json:"from,omitempty"`
+	FromID        string `json:"from_id,omitempty"`
+	DateUnixtime  string `json:"date_unixtime,omitempty"`
+	Edited        string `json:"edited,omitempty"`
+	ForwardedFrom string `json:"forwarded_from,omitempty"`
+	// Note is the message text without the shared URLs.
+	Note string `json:"note,omitempty"`
+}
+
 type LinkPreviewOutput struct {
 	ID      int         `json:"id"`
+	UID     string      `json:"uid,omitempty"`
 	Date    string      `json:"date"`
 	URL     string      `json:"url"`
+	Tags    []string    `json:"tags,omitempty"`
+	Chat    string      `json:"chat,omitempty"`
+	ChatID  int64       `json:"chat_id,omitempty"`
 	Preview interface{} `json:"preview"`
+	// DisplayURL is the Unicode form of URL, see URLRecord.DisplayURL.
+	DisplayURL string `json:"display_url,omitempty"`
+	// SourceURL is the URL that redirected to URL, see URLRecord.SourceURL.
+	SourceURL string `json:"source_url,omitempty"`
+	// HintOnly marks previews made of the preview hint alone because the URL
+	// could not be fetched. They are not cached, so the URL is fetched again.
+	HintOnly bool `json:"hint_only,omitempty"`
 }
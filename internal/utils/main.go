@@ -5,9 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/url"
 	"os"
 	"regexp"
+	"strings"
+
+	"golang.org/x/net/idna"
 )
 
 // HandleError handles errors by logging them with context.
@@ -51,12 +55,95 @@ func WriteJSONFile(filePath string, v interface{}) error {
 	return nil
 }
 
+// Reasons for which CheckURL rejects a URL.
+var (
+	ErrUnsupportedScheme = errors.New("unsupported scheme")
+	ErrMissingHost       = errors.New("missing host")
+	ErrInvalidHost       = errors.New("invalid host")
+)
+
+// IsValidURL reports whether rawURL is an http or https URL with a valid host.
 func IsValidURL(rawURL string) bool {
+	return CheckURL(rawURL) == nil
+}
+
+// CheckURL returns why rawURL is not a valid http or https URL, or nil if it
+// is. Hosts must be IP addresses or domain names whose labels are valid
+// internationalized domain names.
+func CheckURL(rawURL string) error {
 	parsedURL, err := url.Parse(rawURL)
-	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
-		return false
+	if err != nil {
+		return err
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return fmt.Errorf("%w %q", ErrUnsupportedScheme, parsedURL.Scheme)
+	}
+	if parsedURL.Hostname() == "" {
+		return ErrMissingHost
+	}
+	if _, err = ASCIIHost(parsedURL.Hostname()); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidHost, err)
+	}
+	return nil
+}
+
+// ASCIIHost returns host with its internationalized labels converted to
+// punycode and lowercased, as used for DNS lookups. IP addresses are returned
+// unchanged.
+func ASCIIHost(host string) (string, error) {
+	if net.ParseIP(host) != nil {
+		return host, nil
+	}
+	asciiHost, err := hostProfile().ToASCII(strings.TrimSuffix(host, "."))
+	if err != nil {
+		return "", err
+	}
+	for _, label := range strings.Split(asciiHost, ".") {
+		if err = checkHostLabel(label); err != nil {
+			return "", err
+		}
+	}
+	return asciiHost, nil
+}
+
+// checkHostLabel rejects ASCII labels with characters other than letters,
+// digits, hyphens and underscores, or with a leading or trailing hyphen.
+func checkHostLabel(label string) error {
+	if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+		return fmt.Errorf("label %q starts or ends with a hyphen", label)
+	}
+	for _, c := range label {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+			return fmt.Errorf("invalid character %q in label %q", c, label)
+		}
+	}
+	return nil
+}
+
+// UnicodeHost returns host with its punycode labels decoded for display. Hosts
+// that cannot be decoded are returned unchanged.
+func UnicodeHost(host string) string {
+	if net.ParseIP(host) != nil {
+		return host
 	}
-	return true
+	unicodeHost, err := idna.Display.ToUnicode(host)
+	if err != nil {
+		return host
+	}
+	return unicodeHost
+}
+
+// hostProfile validates and maps host names following IDNA 2008 for lookups,
+// but accepts underscores and the "--" of generated subdomains, which are
+// common in practice.
+func hostProfile() *idna.Profile {
+	return idna.New(
+		idna.MapForLookup(),
+		idna.BidiRule(),
+		idna.VerifyDNSLength(true),
+		idna.StrictDomainName(false),
+		idna.CheckHyphens(false),
+	)
 }
 
 func CompileIgnoreRegex() (*regexp.Regexp, error) {
@@ -1,6 +1,7 @@
 package utils_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -116,6 +117,44 @@ func TestIsValidURL(t *testing.T) {
 	}
 }
 
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected error
+	}{
+		{"https://b\u00fccher.de/", nil},
+		{"https://xn--bcher-kva.de/", nil},
+		{"https://my_host.example.com/", nil},
+		{"http://127.0.0.1:8080/", nil},
+		{"http://[::1]/", nil},
+		{"ftp://example.com", utils.ErrUnsupportedScheme},
+		{"http://", utils.ErrMissingHost},
+		{"https://xn--zz.de/", utils.ErrInvalidHost},
+		{"https://-example.com/", utils.ErrInvalidHost},
+		{"https://exa\u200dmple.com/", utils.ErrInvalidHost},
+		{"https://a..b/", utils.ErrInvalidHost},
+	}
+	for _, tt := range tests {
+		err := utils.CheckURL(tt.url)
+		if tt.expected == nil && err != nil {
+			t.Errorf("CheckURL(%q) = %v, expected nil", tt.url, err)
+		}
+		if tt.expected != nil && !errors.Is(err, tt.expected) {
+			t.Errorf("CheckURL(%q) = %v, expected %v", tt.url, err, tt.expected)
+		}
+	}
+}
+
+func TestASCIIHost(t *testing.T) {
+	asciiHost, err := utils.ASCIIHost("B\u00fccher.DE.")
+	if err != nil || asciiHost != "xn--bcher-kva.de" {
+		t.Errorf("ASCIIHost = %q, %v, expected xn--bcher-kva.de", asciiHost, err)
+	}
+	if unicodeHost := utils.UnicodeHost(asciiHost); unicodeHost != "b\u00fccher.de" {
+		t.Errorf("UnicodeHost(%q) = %q, expected b\u00fccher.de", asciiHost, unicodeHost)
+	}
+}
+
 func TestCompileIgnoreRegex(t *testing.T) {
 	t.Run("NoPattern", func(t *testing.T) {
 		t.Setenv("IMPORT_IGNORE", "")
@@ -0,0 +1,180 @@
+package validation
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"link-builder/internal/utils"
+)
+
+// CanonicalOptions enables the canonicalizations of CanonicalURL that may
+// merge URLs of different resources on some sites.
+type CanonicalOptions struct {
+	// FoldWWW removes a leading "www." from the host.
+	FoldWWW bool
+	// FoldTrailingSlash removes a trailing slash from the path.
+	FoldTrailingSlash bool
+}
+
+// defaultPorts maps URL schemes to the port implied when none is given.
+func defaultPorts() map[string]string {
+	return map[string]string{"http": "80", "https": "443"}
+}
+
+// CanonicalURL returns the canonical form of rawURL following the syntax-based
+// normalization of RFC 3986: the scheme and host are lowercased, with
+// internationalized hosts in punycode form, default ports removed, dot
+// segments resolved, percent-encoding normalized, query parameters sorted and
+// empty queries and fragments dropped. An http URL on the https port 443 is
+// taken to be an https URL. URLs with the same canonical form refer to the same
+// resource, so it serves as deduplication key while the original URL is kept
+// for display.
+func CanonicalURL(rawURL string, options CanonicalOptions) (string, error) {
+	parsedURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+
+	var canonical strings.Builder
+	scheme := strings.ToLower(parsedURL.Scheme)
+	if scheme == "http" && parsedURL.Port() == defaultPorts()["https"] {
+		scheme = "https"
+	}
+	if scheme != "" {
+		canonical.WriteString(scheme + ":")
+	}
+	if parsedURL.Host != "" || parsedURL.User != nil {
+		canonical.WriteString("//")
+		if parsedURL.User != nil {
+			canonical.WriteString(parsedURL.User.String() + "@")
+		}
+		canonical.WriteString(canonicalHost(parsedURL, scheme, options))
+	}
+
+	path := removeDotSegments(normalizePercentEncoding(parsedURL.EscapedPath()))
+	if options.FoldTrailingSlash && len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	if path == "" && parsedURL.Host != "" {
+		path = "/"
+	}
+	canonical.WriteString(path)
+
+	if query := canonicalQuery(parsedURL.RawQuery); query != "" {
+		canonical.WriteString("?" + query)
+	}
+	return canonical.String(), nil
+}
+
+// canonicalHost returns the lowercased punycode host of parsedURL, without a
+// default port and trailing dot.
+func canonicalHost(parsedURL *url.URL, scheme string, options CanonicalOptions) string {
+	host := strings.TrimSuffix(strings.ToLower(parsedURL.Hostname()), ".")
+	if asciiHost, err := utils.ASCIIHost(host); err == nil {
+		host = asciiHost
+	}
+	if options.FoldWWW {
+		host = strings.TrimPrefix(host, "www.")
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	port := parsedURL.Port()
+	if port == "" || port == defaultPorts()[scheme] {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), port)
+}
+
+// canonicalQuery normalizes the percent-encoding of the query parameters,
+// drops empty ones and sorts them. Parameters with the same key keep their
+// relative order.
+func canonicalQuery(rawQuery string) string {
+	var params []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param != "" {
+			params = append(params, normalizePercentEncoding(param))
+		}
+	}
+	sort.SliceStable(params, func(i, j int) bool {
+		keyI, _, _ := strings.Cut(params[i], "=")
+		keyJ, _, _ := strings.Cut(params[j], "=")
+		return keyI < keyJ
+	})
+	return strings.Join(params, "&")
+}
+
+// removeDotSegments resolves the "." and ".." segments of path as described
+// in RFC 3986, section 5.2.4.
+func removeDotSegments(path string) string {
+	if !strings.Contains(path, ".") {
+		return path
+	}
+	var output []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch segment {
+		case ".":
+			if last {
+				output = append(output, "")
+			}
+		case "..":
+			if len(output) > 1 {
+				output = output[:len(output)-1]
+			}
+			if last {
+				output = append(output, "")
+			}
+		default:
+			output = append(output, segment)
+		}
+	}
+	return strings.Join(output, "/")
+}
+
+// normalizePercentEncoding decodes percent-encoded unreserved characters and
+// uppercases the hex digits of the remaining percent-encodings.
+func normalizePercentEncoding(value string) string {
+	if !strings.Contains(value, "%") {
+		return value
+	}
+	var normalized strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' || i+2 >= len(value) || !isHex(value[i+1]) || !isHex(value[i+2]) {
+			normalized.WriteByte(value[i])
+			continue
+		}
+		decoded := unhex(value[i+1])<<4 | unhex(value[i+2])
+		if isUnreserved(decoded) {
+			normalized.WriteByte(decoded)
+		} else {
+			normalized.WriteString(strings.ToUpper(value[i : i+3]))
+		}
+		i += 2
+	}
+	return normalized.String()
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// isUnreserved reports whether c may appear unencoded anywhere in a URL.
+func isUnreserved(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
@@ -0,0 +1,43 @@
+package validation_test
+
+import (
+	"testing"
+
+	"link-builder/internal/validation"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		options  validation.CanonicalOptions
+		expected string
+	}{
+		{"HTTPS://Example.COM:443/a/../b/?", validation.CanonicalOptions{}, "https://example.com/b/"},
+		{"HTTP://Example.com:443/a/../b/?", validation.CanonicalOptions{}, "https://example.com/b/"},
+		{"http://example.com:80", validation.CanonicalOptions{}, "http://example.com/"},
+		{"http://example.com:8080/", validation.CanonicalOptions{}, "http://example.com:8080/"},
+		{"https://example.com./a/./b/../c#top", validation.CanonicalOptions{}, "https://example.com/a/c"},
+		{"https://example.com/%7euser/%2fdocs%3f", validation.CanonicalOptions{}, "https://example.com/~user/%2Fdocs%3F"},
+		{"https://example.com/?b=2&a=1&&a=0", validation.CanonicalOptions{}, "https://example.com/?a=1&a=0&b=2"},
+		{"https://example.com/?q=%e2%82%ac", validation.CanonicalOptions{}, "https://example.com/?q=%E2%82%AC"},
+		{"https://user@[2001:DB8::1]:443/", validation.CanonicalOptions{}, "https://user@[2001:db8::1]/"},
+		{"https://[2001:db8::1]:8443/", validation.CanonicalOptions{}, "https://[2001:db8::1]:8443/"},
+		{"https://www.example.com/b/", validation.CanonicalOptions{FoldWWW: true}, "https://example.com/b/"},
+		{"https://www.example.com/b/", validation.CanonicalOptions{FoldTrailingSlash: true}, "https://www.example.com/b"},
+		{"https://example.com/", validation.CanonicalOptions{FoldTrailingSlash: true}, "https://example.com/"},
+	}
+	for _, tt := range tests {
+		canonical, err := validation.CanonicalURL(tt.input, tt.options)
+		if err != nil {
+			t.Errorf("CanonicalURL(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if canonical != tt.expected {
+			t.Errorf("CanonicalURL(%q, %+v) = %q, expected %q", tt.input, tt.options, canonical, tt.expected)
+		}
+	}
+
+	if _, err := validation.CanonicalURL("http://[::1", validation.CanonicalOptions{}); err == nil {
+		t.Errorf("Expected error for unparsable URL, got nil")
+	}
+}
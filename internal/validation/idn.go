@@ -0,0 +1,107 @@
+package validation
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+
+	"link-builder/internal/utils"
+)
+
+// scriptTables returns the scripts told apart by IsHomograph. Letters of other
+// scripts count as one further script.
+func scriptTables() map[string]*unicode.RangeTable {
+	return map[string]*unicode.RangeTable{
+		"Latin":      unicode.Latin,
+		"Cyrillic":   unicode.Cyrillic,
+		"Greek":      unicode.Greek,
+		"Armenian":   unicode.Armenian,
+		"Georgian":   unicode.Georgian,
+		"Cherokee":   unicode.Cherokee,
+		"Hebrew":     unicode.Hebrew,
+		"Arabic":     unicode.Arabic,
+		"Devanagari": unicode.Devanagari,
+		"Thai":       unicode.Thai,
+		"Han":        unicode.Han,
+		"Hiragana":   unicode.Hiragana,
+		"Katakana":   unicode.Katakana,
+		"Hangul":     unicode.Hangul,
+		"Bopomofo":   unicode.Bopomofo,
+	}
+}
+
+// allowedScriptMixes are the script combinations of regular Chinese, Japanese
+// and Korean domain names, which may also contain Latin letters.
+func allowedScriptMixes() [][]string {
+	return [][]string{
+		{"Latin", "Han", "Hiragana", "Katakana"},
+		{"Latin", "Han", "Hangul"},
+		{"Latin", "Han", "Bopomofo"},
+	}
+}
+
+// DisplayURL returns rawURL with its host in Unicode form, for display. Hosts
+// that may be homographs keep their punycode form, as in browsers.
+func DisplayURL(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || IsHomograph(parsedURL.Hostname()) {
+		return rawURL
+	}
+	asciiHost := parsedURL.Hostname()
+	unicodeHost := utils.UnicodeHost(asciiHost)
+	if unicodeHost == asciiHost {
+		return rawURL
+	}
+	scheme, rest, found := strings.Cut(rawURL, "://")
+	if !found {
+		return rawURL
+	}
+	return scheme + "://" + strings.Replace(rest, asciiHost, unicodeHost, 1)
+}
+
+// IsHomograph reports whether a label of host, in ASCII or Unicode form, mixes
+// letters of different scripts, like a Cyrillic "а" among Latin letters. Such
+// hosts can imitate the host of another site.
+func IsHomograph(host string) bool {
+	for _, label := range strings.Split(utils.UnicodeHost(host), ".") {
+		if mixesScripts(label) {
+			return true
+		}
+	}
+	return false
+}
+
+func mixesScripts(label string) bool {
+	tables := scriptTables()
+	scripts := make(map[string]bool)
+	for _, c := range label {
+		if !unicode.IsLetter(c) {
+			continue
+		}
+		script := "Other"
+		for name, table := range tables {
+			if unicode.Is(table, c) {
+				script = name
+				break
+			}
+		}
+		scripts[script] = true
+	}
+	if len(scripts) <= 1 {
+		return false
+	}
+	for _, mix := range allowedScriptMixes() {
+		allowed := true
+		for script := range scripts {
+			if !slices.Contains(mix, script) {
+				allowed = false
+				break
+			}
+		}
+		if allowed {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package validation_test
+
+import (
+	"testing"
+
+	"link-builder/internal/validation"
+)
+
+func TestNormalizeURLPunycode(t *testing.T) {
+	normalizedURL, err := validation.NormalizeURL("https://Bücher.de:8443/suche?q=go")
+	if err != nil {
+		t.Fatalf("NormalizeURL failed: %v", err)
+	}
+	if expected := "https://xn--bcher-kva.de:8443/suche?q=go"; normalizedURL != expected {
+		t.Errorf("Expected %q, got %q", expected, normalizedURL)
+	}
+
+	unicodeKey, err := validation.CanonicalURL("https://bücher.de/", validation.CanonicalOptions{})
+	if err != nil {
+		t.Fatalf("CanonicalURL failed: %v", err)
+	}
+	asciiKey, err := validation.CanonicalURL("https://xn--bcher-kva.de/", validation.CanonicalOptions{})
+	if err != nil {
+		t.Fatalf("CanonicalURL failed: %v", err)
+	}
+	if unicodeKey != asciiKey {
+		t.Errorf("Expected equal canonical forms, got %q and %q", unicodeKey, asciiKey)
+	}
+}
+
+func TestDisplayURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"https://xn--bcher-kva.de:8443/xn--bcher-kva", "https://bücher.de:8443/xn--bcher-kva"},
+		{"https://example.com/", "https://example.com/"},
+		// A Cyrillic "а" among Latin letters keeps the punycode form.
+		{"https://xn--pple-43d.com/", "https://xn--pple-43d.com/"},
+	}
+	for _, tt := range tests {
+		if display := validation.DisplayURL(tt.input); display != tt.expected {
+			t.Errorf("DisplayURL(%q) = %q, expected %q", tt.input, display, tt.expected)
+		}
+	}
+}
+
+func TestIsHomograph(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected bool
+	}{
+		{"example.com", false},
+		{"bücher.de", false},
+		{"пример.рф", false},
+		{"例え.jp", false},
+		{"goテスト.jp", false},
+		{"аpple.com", true},
+		{"xn--pple-43d.com", true},
+		{"pαypal.com", true},
+	}
+	for _, tt := range tests {
+		if homograph := validation.IsHomograph(tt.host); homograph != tt.expected {
+			t.Errorf("IsHomograph(%q) = %v, expected %v", tt.host, homograph, tt.expected)
+		}
+	}
+}
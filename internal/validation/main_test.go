@@ -8,9 +8,8 @@ import (
 )
 
 const (
-	exampleCom  = "http://example.com"
-	exampleOrg  = "http://example.org"
-	exampleDate = "2025-05-01"
+	exampleCom = "http://example.com"
+	exampleOrg = "http://example.org"
 )
 
 func TestIgnoreRegex(t *testing.T) {
@@ -29,66 +28,27 @@ func TestIgnoreRegex(t *testing.T) {
 	})
 }
 
-func TestValidateURLsConcurrently(t *testing.T) {
-	urls := []string{
-		exampleCom,
-		"https://example.com",
-		"invalid-url",
-	}
-
-	ignoreRegex := regexp.MustCompile("^https://.*$")
-	validURLs, ignoredCount := validation.ValidateURLsConcurrently(urls, ignoreRegex)
-
-	if len(validURLs) != 1 || !validURLs[exampleCom] {
-		t.Errorf("Unexpected valid URLs: %+v", validURLs)
-	}
-
-	if ignoredCount != 1 {
-		t.Errorf("Expected 1 ignored URL, got %d", ignoredCount)
-	}
-}
-
-func TestProcessURLs(t *testing.T) {
-	validURLs := map[string]bool{
-		exampleCom + ";jsessionid=12345": true,
-		exampleCom + "?sessionid=abc":    true,
-		exampleCom:                       true,
-	}
-
-	processedURLs := validation.ProcessURLs(validURLs)
-
-	if len(processedURLs) != 1 {
-		t.Errorf("Expected 1 processed URL, got %d", len(processedURLs))
-	}
-
-	if _, exists := processedURLs[exampleCom]; !exists {
-		t.Errorf("Expected '%s' in processed URLs", exampleCom)
-	}
-}
-
-func TestEnsureUniqueURLs(t *testing.T) {
-	validURLs := map[string]bool{
-		exampleCom: true,
-		exampleOrg: true,
-	}
-
-	allURLs := []struct {
-		ID   int    `json:"id"`
-		Date string `json:"date"`
-		URL  string `json:"url"`
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
 	}{
-		{ID: 1, Date: exampleDate, URL: exampleCom},
-		{ID: 2, Date: exampleDate, URL: exampleOrg},
-		{ID: 3, Date: exampleDate, URL: "http://example.net"},
-	}
-
-	uniqueURLs := validation.EnsureUniqueURLs(validURLs, allURLs)
-
-	if len(uniqueURLs) != 2 {
-		t.Errorf("Expected 2 unique URLs, got %d", len(uniqueURLs))
+		{exampleCom, exampleCom},
+		{exampleCom + "/path;jsessionid=12345", exampleCom + "/path"},
+		{exampleCom + "/?b=2&sessionid=abc&a=1", exampleCom + "/?a=1&b=2"},
+	}
+	for _, tt := range tests {
+		normalizedURL, err := validation.NormalizeURL(tt.input)
+		if err != nil {
+			t.Errorf("NormalizeURL(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if normalizedURL != tt.expected {
+			t.Errorf("NormalizeURL(%q) = %q, expected %q", tt.input, normalizedURL, tt.expected)
+		}
 	}
 
-	if !uniqueURLs[exampleCom] || !uniqueURLs[exampleOrg] {
-		t.Errorf("Expected '%s' and '%s' in unique URLs", exampleCom, exampleOrg)
+	if _, err := validation.NormalizeURL("http://[::1"); err == nil {
+		t.Errorf("Expected error for unparsable URL, got nil")
 	}
 }
@@ -0,0 +1,104 @@
+package validation
+
+import (
+	"errors"
+	"regexp"
+	"sync"
+
+	"link-builder/internal/utils"
+)
+
+// Status is the outcome of validating and normalizing a URL.
+type Status string
+
+const (
+	StatusValid     Status = "valid"
+	StatusInvalid   Status = "invalid"
+	StatusIgnored   Status = "ignored"
+	StatusDuplicate Status = "duplicate"
+	StatusRewritten Status = "rewritten"
+)
+
+// Machine-readable reasons of a Result.
+const (
+	ReasonBadScheme          = "bad_scheme"
+	ReasonMissingHost        = "missing_host"
+	ReasonInvalidHost        = "invalid_host"
+	ReasonParseError         = "parse_error"
+	ReasonIgnoreRule         = "ignore_rule"
+	ReasonDuplicate          = "duplicate"
+	ReasonAlreadyPresent     = "already_present"
+	ReasonTrackingParameters = "tracking_parameters"
+	ReasonNormalized         = "normalized"
+	ReasonRedirect           = "redirect"
+)
+
+// IgnoreRuleName names the ignore rule of utils.CompileIgnoreRegex in results.
+const IgnoreRuleName = "IMPORT_IGNORE"
+
+// Result describes why a URL was kept, rewritten or dropped. Detail holds the
+// parse error, the name of the ignore rule or the URL a URL was rewritten or
+// redirected to or is a duplicate of.
+type Result struct {
+	URL    string `json:"url"`
+	Status Status `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ValidateURLs validates the distinct URLs of urls concurrently. URLs matching
+// ignoreRegex are ignored.
+func ValidateURLs(urls []string, ignoreRegex *regexp.Regexp) map[string]Result {
+	urlChan := make(chan string, len(urls))
+	queued := make(map[string]bool, len(urls))
+	for _, rawURL := range urls {
+		if !queued[rawURL] {
+			queued[rawURL] = true
+			urlChan <- rawURL
+		}
+	}
+	close(urlChan)
+
+	var (
+		mutex   sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]Result, len(queued))
+	)
+	workerCount := 10
+	for range make([]struct{}, workerCount) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rawURL := range urlChan {
+				result := validateURL(rawURL, ignoreRegex)
+				mutex.Lock()
+				results[rawURL] = result
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func validateURL(rawURL string, ignoreRegex *regexp.Regexp) Result {
+	if ignoreRegex != nil && ignoreRegex.MatchString(rawURL) {
+		return Result{URL: rawURL, Status: StatusIgnored, Reason: ReasonIgnoreRule, Detail: IgnoreRuleName}
+	}
+	err := utils.CheckURL(rawURL)
+	if err == nil {
+		return Result{URL: rawURL, Status: StatusValid}
+	}
+	result := Result{URL: rawURL, Status: StatusInvalid, Detail: err.Error()}
+	switch {
+	case errors.Is(err, utils.ErrUnsupportedScheme):
+		result.Reason = ReasonBadScheme
+	case errors.Is(err, utils.ErrMissingHost):
+		result.Reason = ReasonMissingHost
+	case errors.Is(err, utils.ErrInvalidHost):
+		result.Reason = ReasonInvalidHost
+	default:
+		result.Reason = ReasonParseError
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package validation_test
+
+import (
+	"regexp"
+	"testing"
+
+	"link-builder/internal/validation"
+)
+
+func TestValidateURLs(t *testing.T) {
+	urls := []string{
+		exampleCom,
+		exampleCom,
+		"ftp://example.com",
+		"http://",
+		"https://xn--zz.de/",
+		"http://[::1",
+		"http://ignored.com",
+	}
+	results := validation.ValidateURLs(urls, regexp.MustCompile(`^http://ignored\.com$`))
+
+	expected := map[string]struct {
+		status validation.Status
+		reason string
+	}{
+		exampleCom:           {validation.StatusValid, ""},
+		"ftp://example.com":  {validation.StatusInvalid, validation.ReasonBadScheme},
+		"http://":            {validation.StatusInvalid, validation.ReasonMissingHost},
+		"https://xn--zz.de/": {validation.StatusInvalid, validation.ReasonInvalidHost},
+		"http://[::1":        {validation.StatusInvalid, validation.ReasonParseError},
+		"http://ignored.com": {validation.StatusIgnored, validation.ReasonIgnoreRule},
+	}
+	if len(results) != len(expected) {
+		t.Errorf("Expected %d results, got %+v", len(expected), results)
+	}
+	for rawURL, want := range expected {
+		result := results[rawURL]
+		if result.URL != rawURL || result.Status != want.status || result.Reason != want.reason {
+			t.Errorf("Expected %s to be %s (%s), got %+v", rawURL, want.status, want.reason, result)
+		}
+		if result.Status == validation.StatusInvalid && result.Detail == "" {
+			t.Errorf("Expected a detail for %s, got %+v", rawURL, result)
+		}
+	}
+	if detail := results["http://ignored.com"].Detail; detail != validation.IgnoreRuleName {
+		t.Errorf("Expected ignore rule %s, got %q", validation.IgnoreRuleName, detail)
+	}
+}
@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"link-builder/internal/utils"
+)
+
+// TrackingRules lists the query parameters stripped from URLs during
+// normalization. A rule is a parameter name, or a name prefix followed by "*",
+// both compared case-insensitively.
+type TrackingRules struct {
+	// Global rules apply to every URL.
+	Global []string `json:"global"`
+	// Domains maps hosts to rules that only apply to them and their
+	// subdomains.
+	Domains map[string][]string `json:"domains"`
+}
+
+// DefaultTrackingRules returns the built-in rules: the click identifiers and
+// campaign parameters of the common ad, analytics and newsletter platforms.
+func DefaultTrackingRules() TrackingRules {
+	return TrackingRules{
+		Global: []string{
+			"utm_*",
+			"fbclid",
+			"gclid",
+			"gclsrc",
+			"dclid",
+			"gbraid",
+			"wbraid",
+			"msclkid",
+			"yclid",
+			"twclid",
+			"ttclid",
+			"li_fat_id",
+			"mc_cid",
+			"mc_eid",
+			"igshid",
+			"igsh",
+			"ref_src",
+			"ref_url",
+			"_hsenc",
+			"_hsmi",
+			"mkt_tok",
+			"oly_anon_id",
+			"oly_enc_id",
+			"vero_id",
+			"wickedid",
+		},
+		Domains: map[string][]string{
+			"youtube.com":      {"si", "feature", "pp"},
+			"youtu.be":         {"si", "feature"},
+			"open.spotify.com": {"si"},
+			"twitter.com":      {"s", "t"},
+			"x.com":            {"s", "t"},
+			"instagram.com":    {"hl"},
+			"linkedin.com":     {"trk", "trackingId", "refId"},
+			"amazon.com":       {"ref", "ref_", "pd_rd_*", "pf_rd_*", "content-id"},
+		},
+	}
+}
+
+// LoadTrackingRules reads a JSON file of rules in the format of TrackingRules,
+// e.g. {"global": ["ref"], "domains": {"example.com": ["src"]}}, and returns
+// them added to the built-in rules.
+func LoadTrackingRules(filePath string) (TrackingRules, error) {
+	var fileRules TrackingRules
+	if err := utils.ReadJSONFile(filePath, &fileRules); err != nil {
+		return TrackingRules{}, fmt.Errorf("loading tracking rules: %w", err)
+	}
+	rules := DefaultTrackingRules()
+	rules.Global = append(rules.Global, fileRules.Global...)
+	for domain, domainRules := range fileRules.Domains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+		rules.Domains[domain] = append(rules.Domains[domain], domainRules...)
+	}
+	return rules, nil
+}
+
+// matches reports whether the query parameter key of a URL with the given
+// host is a tracking parameter.
+func (r TrackingRules) matches(host, key string) bool {
+	if slices.ContainsFunc(r.Global, func(rule string) bool { return matchesRule(rule, key) }) {
+		return true
+	}
+	for domain, rules := range r.Domains {
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		if slices.ContainsFunc(rules, func(rule string) bool { return matchesRule(rule, key) }) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesRule(rule, key string) bool {
+	if prefix, found := strings.CutSuffix(rule, "*"); found {
+		return len(key) >= len(prefix) && strings.EqualFold(key[:len(prefix)], prefix)
+	}
+	return strings.EqualFold(rule, key)
+}
+
+// strip removes the tracking parameters from query and returns their names in
+// sorted order.
+func (r TrackingRules) strip(host string, query url.Values) []string {
+	host = strings.ToLower(host)
+	var stripped []string
+	for key := range query {
+		if r.matches(host, key) {
+			query.Del(key)
+			stripped = append(stripped, key)
+		}
+	}
+	slices.Sort(stripped)
+	return stripped
+}
@@ -0,0 +1,80 @@
+package validation_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"link-builder/internal/utils"
+	"link-builder/internal/validation"
+)
+
+func TestNormalizeURLTrackingParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{exampleCom + "/a?utm_source=feed&utm_Medium=rss&id=1", exampleCom + "/a?id=1"},
+		{exampleCom + "/?fbclid=abc&gclid=def&mc_eid=1&igshid=2&ref_src=twsrc", exampleCom + "/"},
+		{"https://www.youtube.com/watch?v=abc&si=xyz", "https://www.youtube.com/watch?v=abc"},
+		{exampleCom + "/?v=abc&si=xyz", exampleCom + "/?si=xyz&v=abc"},
+		{exampleCom + "/?utm=kept", exampleCom + "/?utm=kept"},
+	}
+	for _, tt := range tests {
+		normalizedURL, err := validation.NormalizeURL(tt.input)
+		if err != nil {
+			t.Errorf("NormalizeURL(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if normalizedURL != tt.expected {
+			t.Errorf("NormalizeURL(%q) = %q, expected %q", tt.input, normalizedURL, tt.expected)
+		}
+	}
+}
+
+func TestNormalizeURLWithRules(t *testing.T) {
+	rules := validation.TrackingRules{
+		Global:  []string{"src"},
+		Domains: map[string][]string{"example.org": {"ref*"}},
+	}
+	normalizedURL, stripped, err := validation.NormalizeURLWithRules(
+		"https://blog.example.org/post?src=mail&referrer=a&ref=b&utm_source=kept&page=2",
+		rules,
+	)
+	if err != nil {
+		t.Fatalf("NormalizeURLWithRules failed: %v", err)
+	}
+	if expected := "https://blog.example.org/post?page=2&utm_source=kept"; normalizedURL != expected {
+		t.Errorf("Expected %q, got %q", expected, normalizedURL)
+	}
+	if expected := []string{"ref", "referrer", "src"}; !reflect.DeepEqual(stripped, expected) {
+		t.Errorf("Expected stripped %v, got %v", expected, stripped)
+	}
+}
+
+func TestLoadTrackingRules(t *testing.T) {
+	rulesFile := utils.CreateTempFile(t, `{"global": ["src"], "domains": {".Example.org": ["ref"]}}`, "tracking.json")
+	defer os.Remove(rulesFile)
+
+	rules, err := validation.LoadTrackingRules(rulesFile)
+	if err != nil {
+		t.Fatalf("LoadTrackingRules failed: %v", err)
+	}
+	normalizedURL, stripped, err := validation.NormalizeURLWithRules(
+		exampleOrg+"/?ref=a&src=b&fbclid=c&id=1",
+		rules,
+	)
+	if err != nil {
+		t.Fatalf("NormalizeURLWithRules failed: %v", err)
+	}
+	if expected := exampleOrg + "/?id=1"; normalizedURL != expected {
+		t.Errorf("Expected %q, got %q", expected, normalizedURL)
+	}
+	if expected := []string{"fbclid", "ref", "src"}; !reflect.DeepEqual(stripped, expected) {
+		t.Errorf("Expected stripped %v, got %v", expected, stripped)
+	}
+
+	if _, err = validation.LoadTrackingRules(rulesFile + ".missing"); err == nil {
+		t.Error("Expected error for missing rules file, got nil")
+	}
+}
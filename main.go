@@ -2,11 +2,18 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"link-builder/internal/imports"
 	"link-builder/internal/previews"
+	"link-builder/internal/redirects"
+	"link-builder/internal/validation"
 )
 
 const urlsJSONPath = "dist/urls.json"
@@ -15,6 +22,35 @@ type Config struct {
 	ImportInputFilePath   string
 	ImportOutputFilePath  string
 	ProcessImports        bool
+	ImportFormat          string
+	ImportAppend          bool
+	ImportIDMode          string
+	ImportContext         bool
+	ImportTagCaseFold     bool
+	ImportTagAliases      string
+	ImportTrackingRules   string
+	ImportFoldWWW         bool
+	ImportFoldSlash       bool
+	ImportReport          string
+	ImportRedirects       string
+	ImportRedirectHops    int
+	ImportRedirectTimeout time.Duration
+	ImportRedirectCache   string
+	ImportChatNames       string
+	ImportChatIDs         string
+	ImportChatTypes       string
+	ImportDateLayouts     string
+	ImportHistoryVisits   int
+	ImportHistorySince    string
+	ImportHistoryUntil    string
+	ImportHistoryDomains  string
+	ImportDateUnixtime    bool
+	ImportInvalidDates    string
+	Timezone              string
+	Since                 string
+	Until                 string
+	From                  string
+	Match                 string
 	PreviewInputFilePath  string
 	PreviewOutputFilePath string
 	GeneratePreviews      bool
@@ -25,6 +61,13 @@ func loadConfig() Config {
 	config := Config{
 		ImportInputFilePath:   "imports/export.json",
 		ImportOutputFilePath:  urlsJSONPath,
+		ImportFormat:          imports.FormatAuto,
+		ImportIDMode:          imports.IDModeCounter,
+		ImportInvalidDates:    imports.InvalidDatesFlag,
+		ImportRedirectHops:    redirects.DefaultMaxHops,
+		ImportRedirectTimeout: redirects.DefaultTimeout,
+		ImportRedirectCache:   "dist/redirects.json",
+		Timezone:              "UTC",
 		ProcessImports:        false,
 		PreviewInputFilePath:  urlsJSONPath,
 		PreviewOutputFilePath: "dist/previews.json",
@@ -36,7 +79,7 @@ func loadConfig() Config {
 		&config.ImportInputFilePath,
 		"import-input",
 		"imports/export.json",
-		"Path to the input JSON file for import/export",
+		"Comma-separated paths, glob patterns or directories of the input files for import/export",
 	)
 	flag.StringVar(
 		&config.ImportOutputFilePath,
@@ -45,6 +88,161 @@ func loadConfig() Config {
 		"Path to the output JSON file for import/export",
 	)
 	flag.BoolVar(&config.ProcessImports, "import-urls", false, "Import URLs from import/export JSON file")
+	flag.StringVar(
+		&config.ImportFormat,
+		"import-format",
+		imports.FormatAuto,
+		"Format of the import input: "+imports.FormatAuto+" or one of "+
+			strings.Join(imports.DefaultRegistry().Names(), ", "),
+	)
+	flag.BoolVar(
+		&config.ImportAppend,
+		"import-append",
+		false,
+		"Keep the records of an existing import output and append only new URLs",
+	)
+	flag.StringVar(
+		&config.ImportIDMode,
+		"import-id",
+		imports.IDModeCounter,
+		"How to derive the stable uid of URL records: "+strings.Join(imports.IDModes(), ", "),
+	)
+	flag.BoolVar(
+		&config.ImportContext,
+		"import-context",
+		false,
+		"Attach the sender, message ID, timestamps and message text to imported URLs",
+	)
+	flag.BoolVar(&config.ImportTagCaseFold, "import-tag-casefold", false, "Lowercase all imported tags")
+	flag.StringVar(
+		&config.ImportTagAliases,
+		"import-tag-aliases",
+		"",
+		"Path to a JSON file mapping tags to their alias, e.g. {\"golang\": \"go\"}",
+	)
+	flag.StringVar(
+		&config.ImportChatNames,
+		"import-chat-name",
+		"",
+		"Comma-separated chat names to import from a full-account Telegram export",
+	)
+	flag.StringVar(
+		&config.ImportChatIDs,
+		"import-chat-id",
+		"",
+		"Comma-separated chat IDs to import from a full-account Telegram export",
+	)
+	flag.StringVar(
+		&config.ImportChatTypes,
+		"import-chat-type",
+		"",
+		"Comma-separated chat types (e.g. public_channel) to import from a full-account Telegram export",
+	)
+	flag.StringVar(
+		&config.ImportTrackingRules,
+		"import-tracking-rules",
+		"",
+		"JSON file of tracking query parameters to strip, in addition to the built-in ones",
+	)
+	flag.BoolVar(
+		&config.ImportFoldWWW,
+		"import-fold-www",
+		false,
+		"Treat URLs differing only in a leading www. of the host as duplicates",
+	)
+	flag.BoolVar(
+		&config.ImportFoldSlash,
+		"import-fold-trailing-slash",
+		false,
+		"Treat URLs differing only in a trailing slash of the path as duplicates",
+	)
+	flag.StringVar(
+		&config.ImportReport,
+		"import-report",
+		"",
+		"Path of a JSON or CSV (.csv) report of the validation result of every imported URL",
+	)
+	flag.StringVar(
+		&config.ImportRedirects,
+		"import-resolve-redirects",
+		"",
+		"Replace redirecting URLs by their final destination: "+strings.Join(redirects.Modes(), " or "),
+	)
+	flag.IntVar(
+		&config.ImportRedirectHops,
+		"import-redirect-max-hops",
+		config.ImportRedirectHops,
+		"Maximum number of redirects followed per URL",
+	)
+	flag.DurationVar(
+		&config.ImportRedirectTimeout,
+		"import-redirect-timeout",
+		config.ImportRedirectTimeout,
+		"Timeout of resolving the redirects of a single URL",
+	)
+	flag.StringVar(
+		&config.ImportRedirectCache,
+		"import-redirect-cache",
+		config.ImportRedirectCache,
+		"JSON file caching resolved redirects across imports, empty to disable",
+	)
+	flag.StringVar(
+		&config.ImportDateLayouts,
+		"import-date-layouts",
+		"",
+		"'|'-separated Go time layouts of WhatsApp and Signal message timestamps, e.g. \"1/2/06, 15:04\"",
+	)
+	flag.IntVar(
+		&config.ImportHistoryVisits,
+		"import-history-min-visits",
+		0,
+		"Minimum number of visits of URLs imported from a browser history",
+	)
+	flag.StringVar(
+		&config.ImportHistorySince,
+		"import-history-since",
+		"",
+		"Import browser history visits from this date on (YYYY-MM-DD or RFC 3339)",
+	)
+	flag.StringVar(
+		&config.ImportHistoryUntil,
+		"import-history-until",
+		"",
+		"Import browser history visits up to and including this date (YYYY-MM-DD or RFC 3339)",
+	)
+	flag.StringVar(
+		&config.ImportHistoryDomains,
+		"import-history-domains",
+		"",
+		"Comma-separated domains to import from a browser history, including subdomains",
+	)
+	flag.BoolVar(
+		&config.ImportDateUnixtime,
+		"import-date-unixtime",
+		false,
+		"Date Telegram messages by their date_unixtime instead of their local-time date",
+	)
+	flag.StringVar(
+		&config.ImportInvalidDates,
+		"import-invalid-dates",
+		imports.InvalidDatesFlag,
+		"Handling of records with unparsable dates: "+strings.Join(imports.InvalidDateModes(), " or "),
+	)
+	flag.StringVar(&config.Since, "since", "", "Import records dated from this date on (YYYY-MM-DD or RFC 3339)")
+	flag.StringVar(
+		&config.Until,
+		"until",
+		"",
+		"Import records dated up to and including this date (YYYY-MM-DD or RFC 3339)",
+	)
+	flag.StringVar(&config.From, "from", "", "Comma-separated sender names or IDs of the messages to import")
+	flag.StringVar(&config.Match, "match", "", "Regular expression the URL, link text or message text of records must match")
+	flag.StringVar(
+		&config.Timezone,
+		"timezone",
+		"UTC",
+		"IANA timezone of imported dates without one and of the dates written, e.g. Europe/Berlin",
+	)
 
 	flag.StringVar(
 		&config.PreviewInputFilePath,
@@ -69,6 +267,141 @@ func loadConfig() Config {
 	return config
 }
 
+// splitList splits a comma-separated flag value into its trimmed, non-empty items.
+func splitList(value string) []string {
+	return splitListBy(value, ",")
+}
+
+// splitListBy splits a flag value at separator into its trimmed, non-empty items.
+func splitListBy(value, separator string) []string {
+	var items []string
+	for _, item := range strings.Split(value, separator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseDateFlag parses a YYYY-MM-DD or RFC 3339 flag value. A date without a
+// time stands for the start of the day in location, or its end if endOfDay is
+// set.
+func parseDateFlag(value string, endOfDay bool, location *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.ParseInLocation(time.DateOnly, value, location); err == nil {
+		if endOfDay {
+			date = date.AddDate(0, 0, 1).Add(-time.Microsecond)
+		}
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func importOptions(config Config) (imports.Options, error) {
+	options := imports.Options{
+		Format:         config.ImportFormat,
+		Append:         config.ImportAppend,
+		IDMode:         config.ImportIDMode,
+		MessageContext: config.ImportContext,
+		TagCaseFold:    config.ImportTagCaseFold,
+		DateUnixtime:   config.ImportDateUnixtime,
+		InvalidDates:   config.ImportInvalidDates,
+		ReportPath:     config.ImportReport,
+		DateLayouts:    splitListBy(config.ImportDateLayouts, "|"),
+		Filter:         imports.RecordFilter{From: splitList(config.From)},
+		Canonical: validation.CanonicalOptions{
+			FoldWWW:           config.ImportFoldWWW,
+			FoldTrailingSlash: config.ImportFoldSlash,
+		},
+		History: imports.HistoryFilter{
+			MinVisits: config.ImportHistoryVisits,
+			Domains:   splitList(config.ImportHistoryDomains),
+		},
+		ChatFilter: imports.ChatFilter{
+			Names: splitList(config.ImportChatNames),
+			Types: splitList(config.ImportChatTypes),
+		},
+	}
+	for _, rawID := range splitList(config.ImportChatIDs) {
+		chatID, err := strconv.ParseInt(rawID, 10, 64)
+		if err != nil {
+			return options, fmt.Errorf("invalid chat ID %q: %w", rawID, err)
+		}
+		options.ChatFilter.IDs = append(options.ChatFilter.IDs, chatID)
+	}
+	if options.Format != "" && options.Format != imports.FormatAuto {
+		if _, err := imports.DefaultRegistry().Lookup(options.Format); err != nil {
+			return options, err
+		}
+	}
+	if err := options.Validate(); err != nil {
+		return options, err
+	}
+	var err error
+	if options.Timezone, err = time.LoadLocation(config.Timezone); err != nil {
+		return options, fmt.Errorf("invalid -timezone: %w", err)
+	}
+	if options.History.Since, err = parseDateFlag(config.ImportHistorySince, false, options.Timezone); err != nil {
+		return options, fmt.Errorf("invalid -import-history-since: %w", err)
+	}
+	if options.History.Until, err = parseDateFlag(config.ImportHistoryUntil, true, options.Timezone); err != nil {
+		return options, fmt.Errorf("invalid -import-history-until: %w", err)
+	}
+	if options.Filter.Since, err = parseDateFlag(config.Since, false, options.Timezone); err != nil {
+		return options, fmt.Errorf("invalid -since: %w", err)
+	}
+	if options.Filter.Until, err = parseDateFlag(config.Until, true, options.Timezone); err != nil {
+		return options, fmt.Errorf("invalid -until: %w", err)
+	}
+	if config.Match != "" {
+		if options.Filter.Text, err = regexp.Compile(config.Match); err != nil {
+			return options, fmt.Errorf("invalid -match: %w", err)
+		}
+	}
+	if config.ImportTrackingRules != "" {
+		var rules validation.TrackingRules
+		if rules, err = validation.LoadTrackingRules(config.ImportTrackingRules); err != nil {
+			return options, err
+		}
+		options.TrackingRules = &rules
+	}
+	if options.Redirects, err = redirectOptions(config); err != nil {
+		return options, err
+	}
+	if config.ImportTagAliases != "" {
+		if options.TagAliases, err = imports.LoadTagAliases(config.ImportTagAliases); err != nil {
+			return options, err
+		}
+	}
+	return options, nil
+}
+
+// redirectOptions returns the redirect resolution options of the
+// -import-resolve-redirects mode, or nil if resolution is disabled.
+func redirectOptions(config Config) (*redirects.Options, error) {
+	options := redirects.Options{
+		MaxHops:   config.ImportRedirectHops,
+		Timeout:   config.ImportRedirectTimeout,
+		CachePath: config.ImportRedirectCache,
+	}
+	switch config.ImportRedirects {
+	case "":
+		return nil, nil
+	case redirects.ModeShorteners:
+		options.Hosts = redirects.DefaultShortenerHosts()
+	case redirects.ModeAll:
+	default:
+		return nil, fmt.Errorf(
+			"unknown redirect resolution mode %q, expected one of: %s",
+			config.ImportRedirects,
+			strings.Join(redirects.Modes(), ", "),
+		)
+	}
+	return &options, nil
+}
+
 func main() {
 	log.Println("Starting the URL Processor program")
 
@@ -92,10 +425,17 @@ func main() {
 	}
 
 	if config.ProcessImports {
-		if err := imports.ProcessImport(
-			config.ImportInputFilePath,
-			config.ImportOutputFilePath,
-		); err != nil {
+		options, err := importOptions(config)
+		if err != nil {
+			log.Printf("Error parsing import options: %v", err)
+			os.Exit(1)
+		}
+		inputPaths, err := imports.ExpandInputPaths(splitList(config.ImportInputFilePath))
+		if err != nil {
+			log.Printf("Error resolving import inputs: %v", err)
+			os.Exit(1)
+		}
+		if err = imports.ProcessImports(inputPaths, config.ImportOutputFilePath, options); err != nil {
 			log.Printf("Error processing imports: %v", err)
 			os.Exit(1)
 		}
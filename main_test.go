@@ -1,9 +1,16 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"os/exec"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"link-builder/internal/imports"
+	"link-builder/internal/redirects"
 )
 
 func setupMockFiles(t *testing.T, inputData string, inputFileName string) string {
@@ -87,3 +94,199 @@ func TestMainProgram(t *testing.T) {
 		}
 	})
 }
+
+// defaultConfig returns the flag defaults of loadConfig without parsing flags.
+func defaultConfig() Config {
+	return Config{
+		ImportFormat:       imports.FormatAuto,
+		ImportIDMode:       imports.IDModeCounter,
+		ImportInvalidDates: imports.InvalidDatesFlag,
+		Timezone:           "UTC",
+	}
+}
+
+func TestParseDateFlag(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("Failed to load time zone: %v", err)
+	}
+	tests := []struct {
+		name     string
+		value    string
+		endOfDay bool
+		expected time.Time
+		wantErr  bool
+	}{
+		{"Empty", "", true, time.Time{}, false},
+		{"StartOfDay", "2025-05-01", false, time.Date(2025, 5, 1, 0, 0, 0, 0, berlin), false},
+		{"EndOfDay", "2025-05-01", true, time.Date(2025, 5, 1, 23, 59, 59, 999999000, berlin), false},
+		{"RFC3339", "2025-05-01T12:30:00Z", true, time.Date(2025, 5, 1, 12, 30, 0, 0, time.UTC), false},
+		{"Invalid", "01.05.2025", false, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, err := parseDateFlag(tt.value, tt.endOfDay, berlin)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !date.Equal(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, date)
+			}
+		})
+	}
+}
+
+func TestImportOptionsDates(t *testing.T) {
+	config := defaultConfig()
+	config.Timezone = "Europe/Berlin"
+	config.Since = "2025-05-01"
+	config.Until = "2025-05-01"
+	config.ImportHistorySince = "2025-04-01T00:00:00Z"
+	config.ImportHistoryUntil = "2025-04-30"
+	config.ImportDateLayouts = "02.01.2006 | 2006/01/02"
+
+	options, err := importOptions(config)
+	if err != nil {
+		t.Fatalf("importOptions failed: %v", err)
+	}
+	if options.Timezone.String() != "Europe/Berlin" {
+		t.Errorf("Expected time zone Europe/Berlin, got %v", options.Timezone)
+	}
+	// 2025-05-01 in Berlin is 2025-04-30T22:00:00Z to 2025-05-01T21:59:59.999999Z.
+	if since := options.Filter.Since.UTC(); !since.Equal(time.Date(2025, 4, 30, 22, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected -since at the start of the day in Berlin, got %v", since)
+	}
+	if until := options.Filter.Until.UTC(); !until.Equal(time.Date(2025, 5, 1, 21, 59, 59, 999999000, time.UTC)) {
+		t.Errorf("Expected -until at the end of the day in Berlin, got %v", until)
+	}
+	if since := options.History.Since; !since.Equal(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected -import-history-since 2025-04-01T00:00:00Z, got %v", since)
+	}
+	if until := options.History.Until.UTC(); !until.Equal(time.Date(2025, 4, 30, 21, 59, 59, 999999000, time.UTC)) {
+		t.Errorf("Expected -import-history-until at the end of the day in Berlin, got %v", until)
+	}
+	if layouts := strings.Join(options.DateLayouts, "|"); layouts != "02.01.2006|2006/01/02" {
+		t.Errorf("Expected the trimmed date layouts, got %q", options.DateLayouts)
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		value     string
+		separator string
+		expected  []string
+	}{
+		{"", ",", nil},
+		{" , ,", ",", nil},
+		{"alice", ",", []string{"alice"}},
+		{" alice ,bob,, carol ", ",", []string{"alice", "bob", "carol"}},
+		{"02.01.2006, 15:04 | 2006-01-02", "|", []string{"02.01.2006, 15:04", "2006-01-02"}},
+	}
+	for _, tt := range tests {
+		if items := splitListBy(tt.value, tt.separator); !reflect.DeepEqual(items, tt.expected) {
+			t.Errorf("Expected %q split at %q to be %q, got %q", tt.value, tt.separator, tt.expected, items)
+		}
+	}
+	if items := splitList("a, b"); !reflect.DeepEqual(items, []string{"a", "b"}) {
+		t.Errorf("Expected splitList to split at commas, got %q", items)
+	}
+}
+
+func TestImportOptionsFilters(t *testing.T) {
+	config := defaultConfig()
+	config.From = "Alice, Bob"
+	config.Match = "(?i)golang"
+	config.ImportChatNames = "Saved Messages,Links"
+	config.ImportChatIDs = "1, -1002"
+	config.ImportChatTypes = "personal_chat"
+
+	options, err := importOptions(config)
+	if err != nil {
+		t.Fatalf("importOptions failed: %v", err)
+	}
+	if !reflect.DeepEqual(options.Filter.From, []string{"Alice", "Bob"}) {
+		t.Errorf("Expected senders Alice and Bob, got %q", options.Filter.From)
+	}
+	if options.Filter.Text == nil || !options.Filter.Text.MatchString("Learning GoLang") {
+		t.Errorf("Expected -match to compile to a case-insensitive pattern, got %v", options.Filter.Text)
+	}
+	expected := imports.ChatFilter{
+		Names: []string{"Saved Messages", "Links"},
+		IDs:   []int64{1, -1002},
+		Types: []string{"personal_chat"},
+	}
+	if !reflect.DeepEqual(options.ChatFilter, expected) {
+		t.Errorf("Expected chat filter %+v, got %+v", expected, options.ChatFilter)
+	}
+	if options.Redirects != nil {
+		t.Errorf("Expected redirect resolution to be disabled by default, got %+v", options.Redirects)
+	}
+}
+
+func TestRedirectOptions(t *testing.T) {
+	config := defaultConfig()
+	config.ImportRedirectHops = 3
+	config.ImportRedirectTimeout = time.Second
+	config.ImportRedirectCache = "redirects.json"
+
+	config.ImportRedirects = redirects.ModeShorteners
+	options, err := redirectOptions(config)
+	if err != nil {
+		t.Fatalf("redirectOptions failed: %v", err)
+	}
+	expected := redirects.Options{
+		Hosts:     redirects.DefaultShortenerHosts(),
+		MaxHops:   3,
+		Timeout:   time.Second,
+		CachePath: "redirects.json",
+	}
+	if options == nil || !reflect.DeepEqual(*options, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, options)
+	}
+
+	config.ImportRedirects = redirects.ModeAll
+	if options, err = redirectOptions(config); err != nil || options == nil || len(options.Hosts) != 0 {
+		t.Errorf("Expected mode all to resolve every host, got %+v, %v", options, err)
+	}
+
+	config.ImportRedirects = "some"
+	if options, err = redirectOptions(config); err == nil {
+		t.Errorf("Expected an error for an unknown mode, got %+v", options)
+	}
+}
+
+func TestImportOptionsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		err    error
+	}{
+		{"Timezone", func(c *Config) { c.Timezone = "Mars/Olympus_Mons" }, nil},
+		{"Since", func(c *Config) { c.Since = "yesterday" }, nil},
+		{"Until", func(c *Config) { c.Until = "2025-13-01" }, nil},
+		{"HistorySince", func(c *Config) { c.ImportHistorySince = "2025-05" }, nil},
+		{"HistoryUntil", func(c *Config) { c.ImportHistoryUntil = "tomorrow" }, nil},
+		{"Format", func(c *Config) { c.ImportFormat = "myspace" }, imports.ErrUnknownFormat},
+		{"IDMode", func(c *Config) { c.ImportIDMode = "random" }, nil},
+		{"InvalidDates", func(c *Config) { c.ImportInvalidDates = "ignore" }, nil},
+		{"Match", func(c *Config) { c.Match = "([a-z" }, nil},
+		{"ChatIDs", func(c *Config) { c.ImportChatIDs = "1,two" }, nil},
+		{"Redirects", func(c *Config) { c.ImportRedirects = "everything" }, nil},
+		{"TrackingRules", func(c *Config) { c.ImportTrackingRules = "missing-rules.json" }, nil},
+		{"TagAliases", func(c *Config) { c.ImportTagAliases = "missing-aliases.json" }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := defaultConfig()
+			tt.modify(&config)
+			_, err := importOptions(config)
+			if err == nil || (tt.err != nil && !errors.Is(err, tt.err)) {
+				t.Errorf("Expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+
+	if _, err := importOptions(defaultConfig()); err != nil {
+		t.Errorf("Expected the defaults to be valid, got %v", err)
+	}
+}